@@ -0,0 +1,12 @@
+// Command stepcheck reports step names in pipeline configs that don't match any AddStep call.
+package main
+
+import (
+	"pipeline/pipeline/stepcheck"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(stepcheck.Analyzer)
+}
@@ -34,14 +34,14 @@ func main() {
 
 	pl.AddInitialInputs("extra input 1", "extra input 2")
 
-	outputs, err := pl.Execute()
+	result, err := pl.Execute()
 	if err != nil {
 		fmt.Println("Pipeline failed:", err)
 		return
 	}
 
 	fmt.Println("=== Pipeline Outputs ===")
-	for stepName, outs := range outputs {
-		fmt.Printf("%s => %v\n", stepName, outs)
+	for _, record := range result.Steps {
+		fmt.Printf("%s => %v (%s)\n", record.Name, record.Outputs, record.Duration)
 	}
 }
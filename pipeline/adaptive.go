@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveLimiter bounds the number of concurrent item calls of adaptive fan-out
+// steps, adjusting the bound AIMD-style: every successful call below
+// LatencyThreshold raises the limit by 1/limit (about one per full round), and
+// every failed or slow call multiplies it by Backoff, never leaving
+// [MinLimit, MaxLimit]. A limiter may be shared across steps and pipelines to
+// protect a common downstream system.
+type AdaptiveLimiter struct {
+	MinLimit         int
+	MaxLimit         int
+	LatencyThreshold time.Duration // zero disables the latency signal
+	Backoff          float64
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    float64
+	inFlight int
+}
+
+// NewAdaptiveLimiter returns a limiter that starts at minLimit and halves on each
+// failure or call slower than latencyThreshold.
+func NewAdaptiveLimiter(minLimit, maxLimit int, latencyThreshold time.Duration) *AdaptiveLimiter {
+	if minLimit < 1 {
+		minLimit = 1
+	}
+	if maxLimit < minLimit {
+		maxLimit = minLimit
+	}
+	return &AdaptiveLimiter{
+		MinLimit:         minLimit,
+		MaxLimit:         maxLimit,
+		LatencyThreshold: latencyThreshold,
+		Backoff:          0.5,
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.init()
+	return int(l.limit)
+}
+
+func (l *AdaptiveLimiter) init() {
+	if l.cond == nil {
+		l.cond = sync.NewCond(&l.mu)
+		l.limit = float64(l.MinLimit)
+	}
+	if l.limit < 1 {
+		l.limit = 1
+	}
+}
+
+// acquire blocks until a call may start under the current limit.
+func (l *AdaptiveLimiter) acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.init()
+	for l.inFlight >= int(l.limit) {
+		l.cond.Wait()
+	}
+	l.inFlight++
+}
+
+// release ends a call started with acquire and adjusts the limit from its outcome.
+func (l *AdaptiveLimiter) release(latency time.Duration, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if failed || (l.LatencyThreshold > 0 && latency > l.LatencyThreshold) {
+		backoff := l.Backoff
+		if backoff <= 0 || backoff >= 1 {
+			backoff = 0.5
+		}
+		l.limit *= backoff
+	} else {
+		l.limit += 1 / l.limit
+	}
+	if min := float64(l.MinLimit); l.limit < min {
+		l.limit = min
+	}
+	if max := float64(l.MaxLimit); max >= 1 && l.limit > max {
+		l.limit = max
+	}
+	l.cond.Broadcast()
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// AssertionError is returned when the steps of a run succeeded but some assertion
+// steps failed: the run completed, with data quality failures. Its Failures are the
+// *StepError values of the failed assertions.
+type AssertionError struct {
+	Failures []error
+}
+
+func (e *AssertionError) Error() string {
+	msgs := make([]string, len(e.Failures))
+	for i, err := range e.Failures {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("completed with %d quality failure(s): %s", len(e.Failures), strings.Join(msgs, "; "))
+}
+
+func (e *AssertionError) Unwrap() []error {
+	return e.Failures
+}
+
+// AddAssertStep adds a data quality check run after the main steps, if they all
+// succeeded, and before teardown steps. Its arguments resolve like any step's and
+// it must return an error, possibly after other outputs; every assertion runs even
+// if an earlier one fails. Failed assertions make Execute and Run return an
+// *AssertionError instead of a step failure.
+func (p *Pipeline) AddAssertStep(name string, callable interface{}) error {
+	fnType := reflect.TypeOf(callable)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumOut() == 0 || fnType.Out(fnType.NumOut()-1) != errorType {
+		return fmt.Errorf("assert step %s: callable must be a function returning an error, got %v", name, fnType)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.assertSteps = append(p.assertSteps, Step{Name: name, Callable: callable})
+	p.logger.Debugf("Added assert step %q", name)
+	return nil
+}
+
+// runAssertions executes the assertion steps in registration order and returns an
+// *AssertionError if any of them failed.
+func (p *Pipeline) runAssertions(result *Result) error {
+	var failures []error
+	for _, step := range p.assertSteps {
+		if record, disabled := p.disabledRecord(step); disabled {
+			record.Assertion = true
+			result.Steps = append(result.Steps, record)
+			continue
+		}
+		record := p.runStep(step)
+		record.Assertion = true
+		p.flushLazyRecords(result)
+		record.Err = p.stepError(step, len(result.Steps), record.Err)
+		result.Steps = append(result.Steps, record)
+		if record.Err != nil {
+			p.logger.Warnf("Assertion %q failed: %v", step.Name, record.Err)
+			p.notifyAssertionFailed(step, record.Err)
+			failures = append(failures, record.Err)
+		}
+	}
+	if len(failures) > 0 {
+		return &AssertionError{Failures: failures}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrAuditChainBroken is returned by VerifyAudit when records were altered,
+// removed, inserted or reordered.
+var ErrAuditChainBroken = errors.New("audit chain broken")
+
+// AuditRecord is one link of the hash chain recorded with PipelineConfig.Audit.
+// Hash covers every other field, PrevHash included, so changing any record
+// invalidates it and every record after it.
+type AuditRecord struct {
+	RunID       string    `json:"run_id"`
+	Index       int       `json:"index"`
+	Step        string    `json:"step"`
+	Status      string    `json:"status"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Error       string    `json:"error,omitempty"`
+	InputsHash  string    `json:"inputs_hash,omitempty"` // Empty for steps that were not called.
+	OutputsHash string    `json:"outputs_hash"`
+	PrevHash    string    `json:"prev_hash"` // Empty for the first record of a run.
+	Hash        string    `json:"hash"`
+}
+
+// computeHash returns the hex SHA-256 digest of the record without its Hash.
+func (r *AuditRecord) computeHash() string {
+	h := sha256.New()
+	for _, field := range []string{
+		r.RunID, fmt.Sprint(r.Index), r.Step, r.Status,
+		r.Start.UTC().Format(time.RFC3339Nano), r.End.UTC().Format(time.RFC3339Nano),
+		r.Error, r.InputsHash, r.OutputsHash, r.PrevHash,
+	} {
+		fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyAudit checks that records form an unbroken hash chain, as recorded in
+// Result.Audit. It cannot tell whether records were dropped from the end.
+func VerifyAudit(records []AuditRecord) error {
+	prev := ""
+	for i := range records {
+		r := &records[i]
+		switch {
+		case r.Index != i:
+			return fmt.Errorf("%w: record %d has index %d", ErrAuditChainBroken, i, r.Index)
+		case r.PrevHash != prev:
+			return fmt.Errorf("%w: record %d (%s) does not follow record %d", ErrAuditChainBroken, i, r.Step, i-1)
+		case r.computeHash() != r.Hash:
+			return fmt.Errorf("%w: record %d (%s) was altered", ErrAuditChainBroken, i, r.Step)
+		}
+		prev = r.Hash
+	}
+	return nil
+}
+
+// auditChain chains the step records of result.
+func (p *Pipeline) auditChain(result *Result) []AuditRecord {
+	records := make([]AuditRecord, len(result.Steps))
+	prev := ""
+	for i, step := range result.Steps {
+		outputs := make([]reflect.Value, len(step.Outputs))
+		for j := range step.Outputs {
+			outputs[j] = reflect.ValueOf(&step.Outputs[j]).Elem()
+		}
+		r := AuditRecord{
+			RunID:       result.RunID,
+			Index:       i,
+			Step:        step.Name,
+			Status:      step.Status(),
+			Start:       step.Start,
+			End:         step.End,
+			InputsHash:  step.inputsHash,
+			OutputsHash: p.auditHash(outputs),
+			PrevHash:    prev,
+		}
+		if step.Err != nil {
+			r.Error = step.Err.Error()
+		}
+		r.Hash = r.computeHash()
+		records[i] = r
+		prev = r.Hash
+	}
+	return records
+}
+
+// auditHash hashes values with the configured Hasher. Values it cannot hash, such
+// as channels for JSONHasher, are hashed from their Go syntax representation.
+func (p *Pipeline) auditHash(values []reflect.Value) string {
+	if sum, err := p.hasher().Hash(values); err == nil {
+		return sum
+	}
+	h := sha256.New()
+	for _, v := range values {
+		fmt.Fprintf(h, "%s:%#v;", v.Type(), v.Interface())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,164 @@
+package pipeline
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// parallelBatches groups consecutive steps that can run concurrently with
+// PipelineConfig.AutoParallel. It returns, for the first step of every batch, the
+// number of steps in it; steps that run alone have 1. Dependencies are inferred
+// conservatively from the type-level simulation used by Validate: a step joins the
+// batch before it only if none of its parameters would be resolved from the
+// outputs of a step of that batch. Steps whose resolution depends on something the
+// simulation cannot see (conditions, lazy, streaming or StepRunner steps, restored
+// checkpoints, unresolved parameters) always run alone.
+func (p *Pipeline) parallelBatches(steps []Step, checkpoints map[string]Checkpoint) []int {
+	issues, resolutions := p.simulate()
+	alone := make(map[string]bool)
+	for _, issue := range issues {
+		alone[issue.Step] = true
+	}
+	deps := p.bindingDeps()
+	for _, res := range resolutions {
+		if res.From != nil && res.From.Step != "" {
+			deps[res.Step] = append(deps[res.Step], res.From.Step)
+		}
+		for _, ref := range res.Collected {
+			if ref.Step != "" {
+				deps[res.Step] = append(deps[res.Step], ref.Step)
+			}
+		}
+	}
+
+	lazy := make(map[string]bool)
+	for _, step := range steps {
+		if p.isLazy(step) {
+			lazy[step.Name] = true
+		}
+	}
+	eligible := func(step Step) bool {
+		if alone[step.Name] || lazy[step.Name] || p.isDisabled(step.Name) || isRunner(step) {
+			return false
+		}
+		if _, ok := checkpoints[step.Name]; ok {
+			return false
+		}
+		if stepCfg, ok := p.config.StepConfigs[step.Name]; ok && stepCfg.Condition != nil {
+			return false
+		}
+		fnType := reflect.TypeOf(step.Callable)
+		if fnType == nil || fnType.Kind() != reflect.Func || isStreamingStep(fnType) {
+			return false
+		}
+		for _, dep := range deps[step.Name] {
+			if lazy[dep] {
+				return false // resolving its arguments may run the lazy step
+			}
+		}
+		return true
+	}
+
+	batches := make([]int, len(steps))
+	for i := 0; i < len(steps); {
+		j := i + 1
+		if eligible(steps[i]) {
+			members := map[string]bool{steps[i].Name: true}
+			groups := map[string]bool{p.serialGroup(steps[i]): true}
+		extend:
+			for ; j < len(steps) && eligible(steps[j]); j++ {
+				for _, dep := range deps[steps[j].Name] {
+					if members[dep] {
+						break extend
+					}
+				}
+				group := p.serialGroup(steps[j])
+				if group != "" && groups[group] {
+					break
+				}
+				members[steps[j].Name], groups[group] = true, true
+			}
+		}
+		batches[i] = j - i
+		i = j
+	}
+	return batches
+}
+
+func (p *Pipeline) serialGroup(step Step) string {
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok {
+		return stepCfg.SerialGroup
+	}
+	return ""
+}
+
+// runParallel runs a batch of independent steps: their arguments are resolved in
+// step order, the steps are called concurrently, then their outputs are stored in
+// step order, so that later steps see the context a sequential run would produce.
+func (p *Pipeline) runParallel(steps []Step) []StepRecord {
+	base := p.logger
+	defer func() { p.logger = base }()
+	records := make([]StepRecord, len(steps))
+	calls := make([]*stepCall, len(steps))
+	heartbeats := make([]*heartbeat, len(steps))
+	for i, step := range steps {
+		p.logger = base // stepLogger builds on p.logger
+		p.logger = p.stepLogger(step)
+		p.logger.Infof("Executing step %q in parallel with %d other step(s)", step.Name, len(steps)-1)
+		p.pickCounters = make(map[reflect.Type]int)
+		p.notifyStepStarted(step)
+		records[i] = StepRecord{Name: step.Name, Start: time.Now()}
+		heartbeats[i] = p.startHeartbeat(step)
+		waited, err := p.waitFor(step, heartbeats[i])
+		records[i].Waited = waited
+		if err != nil {
+			records[i].Err = err
+			heartbeats[i].stop()
+			continue
+		}
+		fnValue := reflect.ValueOf(step.Callable)
+		args := make([]reflect.Value, fnValue.Type().NumIn())
+		p.conversions = nil
+		err = p.resolveArgs(step, fnValue.Type(), args)
+		records[i].Conversions = p.conversions
+		if err != nil {
+			records[i].Err = err
+			heartbeats[i].stop()
+			continue
+		}
+		calls[i] = p.prepareCall(step, fnValue, args)
+	}
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		if call == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.workers.acquire(nil)
+			defer p.workers.release()
+			p.invoke(call)
+			records[i].End = time.Now()
+			heartbeats[i].stop()
+		}()
+	}
+	wg.Wait()
+
+	for i, step := range steps {
+		p.logger = base
+		p.logger = p.stepLogger(step)
+		record := &records[i]
+		if call := calls[i]; call != nil {
+			record.Outputs, record.Err = p.storeCall(call)
+			record.Cached, record.inputsHash = call.cached, call.argsHash
+		} else {
+			record.End = time.Now()
+		}
+		record.Duration = record.End.Sub(record.Start)
+		p.notifyStepFinished(step, record.Duration, record.Err)
+	}
+	return records
+}
@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParallelBatches(t *testing.T) {
+	p := NewPipeline(nil, discardLogger)
+	p.AddStep("count", func() int { return 1 })
+	p.AddStep("name", func() string { return "x" })
+	p.AddStep("join", func(n int, s string) bool { return true })
+	p.AddStep("flag", func(b bool) float64 { return 1 })
+	p.AddStep("other", func() uint { return 1 })
+
+	got := p.parallelBatches(p.steps, nil)
+	want := []int{2, 0, 1, 2, 0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parallelBatches = %v, want %v", got, want)
+	}
+}
+
+func TestAutoParallelRunsBatchConcurrently(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.AutoParallel = true
+	p := NewPipeline(cfg, discardLogger)
+	var started sync.WaitGroup
+	started.Add(2)
+	bothStarted := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(bothStarted)
+	}()
+	wait := func() error {
+		started.Done()
+		select {
+		case <-bothStarted:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("the other step of the batch did not start")
+		}
+	}
+	p.AddStep("first", func() (int, error) { return 1, wait() })
+	p.AddStep("second", func() (string, error) { return "x", wait() })
+
+	if _, err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestAutoParallelStoresOutputsInStepOrder(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.AutoParallel = true
+	p := NewPipeline(cfg, discardLogger)
+	p.AddStep("slow", func() int {
+		time.Sleep(20 * time.Millisecond)
+		return 1
+	})
+	p.AddStep("fast", func() int { return 2 })
+	var collected []int
+	p.AddStep("collect", func(ns []int) { collected = ns })
+
+	result, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(collected, want) {
+		t.Errorf("collect got %v, want %v", collected, want)
+	}
+	var names []string
+	for _, record := range result.Steps {
+		names = append(names, record.Name)
+	}
+	if want := []string{"slow", "fast", "collect"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Result.Steps = %v, want %v", names, want)
+	}
+}
+
+func TestAutoParallelFailureWithinBatch(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.AutoParallel = true
+	p := NewPipeline(cfg, discardLogger)
+	errBoom := errors.New("boom")
+	p.AddStep("fail", func() (int, error) { return 0, errBoom })
+	p.AddStep("succeed", func() string {
+		time.Sleep(10 * time.Millisecond)
+		return "done"
+	})
+	ran := false
+	p.AddStep("after", func(s string) { ran = true })
+
+	result, err := p.Run(context.Background())
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Run = %v, want %v", err, errBoom)
+	}
+	if len(result.Steps) != 2 {
+		t.Fatalf("Result.Steps has %d records, want the 2 of the batch", len(result.Steps))
+	}
+	if got := result.Steps[1]; got.Name != "succeed" || got.Err != nil || !reflect.DeepEqual(got.Outputs, []interface{}{"done"}) {
+		t.Errorf("record of succeed = %+v, want its output", got)
+	}
+	if ran {
+		t.Error("the step after the failed batch ran")
+	}
+}
@@ -0,0 +1,147 @@
+package pipeline
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for a step whose circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker short-circuits calls to a flaky step. After FailureThreshold
+// consecutive failures it opens for OpenDuration, failing calls with ErrCircuitOpen;
+// then it lets HalfOpenProbes trial calls through, closing again once they all
+// succeed and reopening on the first failure. A breaker may be shared across
+// steps and pipelines to protect a common dependency.
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+	HalfOpenProbes   int
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	inFlight  int
+	successes int
+}
+
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration, halfOpenProbes int) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	if halfOpenProbes < 1 {
+		halfOpenProbes = 1
+	}
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		OpenDuration:     openDuration,
+		HalfOpenProbes:   halfOpenProbes,
+	}
+}
+
+// State returns the current state, moving from open to half-open once OpenDuration has elapsed.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refresh()
+	return cb.state
+}
+
+func (cb *CircuitBreaker) refresh() {
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.OpenDuration {
+		cb.state = CircuitHalfOpen
+		cb.inFlight, cb.successes = 0, 0
+	}
+}
+
+// allow reports whether a call may proceed; every allowed call must be followed by record.
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refresh()
+	switch cb.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.inFlight+cb.successes >= cb.HalfOpenProbes {
+			return ErrCircuitOpen
+		}
+		cb.inFlight++
+	}
+	return nil
+}
+
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case CircuitHalfOpen:
+		cb.inFlight--
+		if err != nil {
+			cb.trip()
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.HalfOpenProbes {
+			cb.state, cb.failures = CircuitClosed, 0
+		}
+	case CircuitClosed:
+		if err == nil {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.FailureThreshold {
+			cb.trip()
+		}
+	}
+}
+
+func (cb *CircuitBreaker) trip() {
+	cb.state = CircuitOpen
+	cb.openedAt = time.Now()
+	cb.failures = 0
+}
+
+// callStep calls the step function through its circuit breaker, if it has one.
+func (p *Pipeline) callStep(step Step, fnValue reflect.Value, args []reflect.Value) ([]reflect.Value, error) {
+	var cb *CircuitBreaker
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok {
+		cb = stepCfg.CircuitBreaker
+	}
+	if cb != nil {
+		if err := cb.allow(); err != nil {
+			return nil, err
+		}
+	}
+	results := fnValue.Call(args)
+	err := returnedError(fnValue.Type(), results)
+	if cb != nil {
+		cb.record(err)
+	}
+	return results, err
+}
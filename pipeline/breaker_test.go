@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	cb := NewCircuitBreaker(2, 20*time.Millisecond, 1)
+	errBoom := errors.New("boom")
+
+	for i := 0; i < 2; i++ {
+		if err := cb.allow(); err != nil {
+			t.Fatalf("allow while closed: %v", err)
+		}
+		cb.record(errBoom)
+	}
+	if got := cb.State(); got != CircuitOpen {
+		t.Fatalf("State after 2 failures = %s, want open", got)
+	}
+	if err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow while open = %v, want ErrCircuitOpen", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if got := cb.State(); got != CircuitHalfOpen {
+		t.Fatalf("State after OpenDuration = %s, want half-open", got)
+	}
+	if err := cb.allow(); err != nil {
+		t.Fatalf("allow for the probe: %v", err)
+	}
+	if err := cb.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow beyond HalfOpenProbes = %v, want ErrCircuitOpen", err)
+	}
+	cb.record(nil)
+	if got := cb.State(); got != CircuitClosed {
+		t.Errorf("State after a successful probe = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreakerReopensOnFailedProbe(t *testing.T) {
+	cb := NewCircuitBreaker(1, 10*time.Millisecond, 2)
+	errBoom := errors.New("boom")
+	cb.record(errBoom)
+	time.Sleep(20 * time.Millisecond)
+	if err := cb.allow(); err != nil {
+		t.Fatalf("allow for the probe: %v", err)
+	}
+	cb.record(errBoom)
+	if got := cb.State(); got != CircuitOpen {
+		t.Errorf("State after a failed probe = %s, want open", got)
+	}
+}
+
+func TestCircuitBreakerSharedAcrossRuns(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.StepConfigs["call"] = &StepConfig{CircuitBreaker: NewCircuitBreaker(2, time.Hour, 1)}
+	p := NewPipeline(cfg, discardLogger)
+	errBoom := errors.New("boom")
+	calls := 0
+	p.AddStep("call", func() error { calls++; return errBoom })
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Run(context.Background()); !errors.Is(err, errBoom) {
+			t.Fatalf("Run %d = %v, want %v", i+1, err, errBoom)
+		}
+	}
+	_, err := p.Run(context.Background())
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Run 3 = %v, want ErrCircuitOpen", err)
+	}
+	if code := ErrorCodeOf(err); code != CodeCircuitOpen {
+		t.Errorf("ErrorCodeOf = %s, want %s", code, CodeCircuitOpen)
+	}
+	if calls != 2 {
+		t.Errorf("call ran %d times, want 2", calls)
+	}
+}
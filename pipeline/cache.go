@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Hasher computes a stable key from the resolved arguments of a step. It keys the
+// step cache and the audit log (see PipelineConfig.Hasher).
+type Hasher interface {
+	Hash(args []reflect.Value) (string, error)
+}
+
+// HasherFunc adapts a function to the Hasher interface.
+type HasherFunc func(args []reflect.Value) (string, error)
+
+func (f HasherFunc) Hash(args []reflect.Value) (string, error) {
+	return f(args)
+}
+
+// HashKeyer is implemented by values that provide their own hashing key to
+// JSONHasher, such as types whose unexported fields JSON would skip or whose
+// volatile members (timestamps, connections) must not change the key.
+type HashKeyer interface {
+	HashKey() ([]byte, error)
+}
+
+// JSONHasher hashes every argument's type name together with its HashKey if it
+// is a HashKeyer, or its JSON encoding otherwise. It is the default Hasher;
+// values that cannot be JSON-encoded are not cacheable.
+type JSONHasher struct{}
+
+func (JSONHasher) Hash(args []reflect.Value) (string, error) {
+	h := sha256.New()
+	for i, arg := range args {
+		var data []byte
+		var err error
+		if keyer, ok := arg.Interface().(HashKeyer); ok {
+			data, err = keyer.HashKey()
+		} else {
+			data, err = json.Marshal(arg.Interface())
+		}
+		if err != nil {
+			return "", fmt.Errorf("argument %d (%s): %w", i, arg.Type(), err)
+		}
+		fmt.Fprintf(h, "%s:%d:", arg.Type(), len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// StepCache stores the outputs of memoized steps by step name and inputs hash.
+// Set receives the step's StepConfig.CacheTTL, zero meaning the cache's default.
+// Invalidate drops the entry for inputsHash, or every entry of step if it is empty.
+type StepCache interface {
+	Get(step, inputsHash string) ([]interface{}, bool)
+	Set(step, inputsHash string, outputs []interface{}, ttl time.Duration)
+	Invalidate(step, inputsHash string)
+}
+
+type cacheEntry struct {
+	outputs []interface{}
+	expires time.Time // Zero means no expiry.
+}
+
+// MemoryCache is an in-process StepCache whose entries expire after a TTL.
+type MemoryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]map[string]cacheEntry // by step, then inputs hash
+}
+
+// NewMemoryCache creates a MemoryCache; a ttl <= 0 keeps entries forever unless
+// the step sets its own TTL.
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{ttl: ttl, entries: make(map[string]map[string]cacheEntry)}
+}
+
+func (c *MemoryCache) Get(step, inputsHash string) ([]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[step][inputsHash]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(c.entries[step], inputsHash)
+		return nil, false
+	}
+	return entry.outputs, true
+}
+
+func (c *MemoryCache) Set(step, inputsHash string, outputs []interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	entry := cacheEntry{outputs: outputs}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if c.entries[step] == nil {
+		c.entries[step] = make(map[string]cacheEntry)
+	}
+	c.entries[step][inputsHash] = entry
+}
+
+func (c *MemoryCache) Invalidate(step, inputsHash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if inputsHash == "" {
+		delete(c.entries, step)
+		return
+	}
+	delete(c.entries[step], inputsHash)
+}
+
+// memoizeKey returns the inputs hash of a memoized step, or "" if the step is not
+// cached or its arguments cannot be hashed.
+func (p *Pipeline) memoizeKey(step Step, args []reflect.Value) string {
+	stepCfg, ok := p.config.StepConfigs[step.Name]
+	if !ok || !stepCfg.Memoize || p.config.Cache == nil {
+		return ""
+	}
+	key, err := p.hasher().Hash(args)
+	if err != nil {
+		p.logger.Warnf("Step %q not cached: %v", step.Name, err)
+		return ""
+	}
+	return key
+}
+
+// hasher returns the configured Hasher, JSONHasher by default.
+func (p *Pipeline) hasher() Hasher {
+	if p.config.Hasher != nil {
+		return p.config.Hasher
+	}
+	return JSONHasher{}
+}
+
+// outputValues converts cached outputs back into values of the step's declared output types.
+func outputValues(fnType reflect.Type, outputs []interface{}) ([]reflect.Value, bool) {
+	if len(outputs) != fnType.NumOut() {
+		return nil, false
+	}
+	values := make([]reflect.Value, len(outputs))
+	for i, out := range outputs {
+		t := fnType.Out(i)
+		if out == nil {
+			values[i] = reflect.Zero(t)
+			continue
+		}
+		val := reflect.ValueOf(out)
+		if !val.Type().AssignableTo(t) {
+			return nil, false
+		}
+		values[i] = reflect.New(t).Elem()
+		values[i].Set(val)
+	}
+	return values, true
+}
@@ -0,0 +1,248 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrChainCycle is returned when pipeline triggers would run a pipeline again
+// within its own chain.
+var ErrChainCycle = errors.New("pipeline chain cycle")
+
+// Trigger starts another pipeline when a run succeeds; see PipelineConfig.Triggers.
+type Trigger struct {
+	Pipeline string // Name in PipelineConfig.Pipelines.
+
+	// Steps lists the steps whose outputs, in this order, are the initial inputs of
+	// the triggered run. Their outputs are passed whatever the OutputFilter.
+	Steps []string
+
+	// Retries is the number of times a failed triggered run is run again. Once they
+	// are exhausted, its inputs go to PipelineConfig.DeadLetters, if set.
+	Retries int
+}
+
+// TriggeredRun records a run started by a Trigger.
+type TriggeredRun struct {
+	Pipeline string
+	RunID    string
+	Err      error
+}
+
+// PipelineRegistry names the pipelines that triggers can start. It is safe for
+// concurrent use.
+type PipelineRegistry struct {
+	mu        sync.RWMutex
+	pipelines map[string]*Pipeline
+}
+
+func NewPipelineRegistry() *PipelineRegistry {
+	return &PipelineRegistry{pipelines: make(map[string]*Pipeline)}
+}
+
+// Register adds p under name. It fails if the name is taken or if the triggers of
+// the registered pipelines would then form a cycle.
+func (r *PipelineRegistry) Register(name string, p *Pipeline) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.pipelines[name]; exists {
+		return fmt.Errorf("pipeline %s already registered", name)
+	}
+	r.pipelines[name] = p
+	if cycle := r.cycleFrom(name); cycle != nil {
+		delete(r.pipelines, name)
+		return fmt.Errorf("%w: %s", ErrChainCycle, strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// Lookup returns the pipeline registered under name.
+func (r *PipelineRegistry) Lookup(name string) (*Pipeline, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.pipelines[name]
+	return p, ok
+}
+
+// nameOf returns the name p, or the pipeline the run p was taken from, is registered under.
+func (r *PipelineRegistry) nameOf(p *Pipeline) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name, candidate := range r.pipelines {
+		if candidate == p || (p.source != nil && candidate == p.source) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// cycleFrom returns the first chain of triggers leading from name back to a
+// pipeline already in the chain, if there is one. r.mu must be held.
+func (r *PipelineRegistry) cycleFrom(name string) []string {
+	var visit func(path []string) []string
+	visit = func(path []string) []string {
+		p, ok := r.pipelines[path[len(path)-1]]
+		if !ok {
+			return nil
+		}
+		for _, trigger := range p.config.Triggers {
+			next := append(slices.Clone(path), trigger.Pipeline)
+			if slices.Contains(path, trigger.Pipeline) {
+				return next
+			}
+			if cycle := visit(next); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return visit([]string{name})
+}
+
+// chainIssues reports triggers naming unknown pipelines or steps, and trigger cycles.
+func (p *Pipeline) chainIssues() []ValidationIssue {
+	if len(p.config.Triggers) == 0 {
+		return nil
+	}
+	registry := p.config.Pipelines
+	if registry == nil {
+		return []ValidationIssue{{Param: -1, Message: "triggers set without PipelineConfig.Pipelines"}}
+	}
+	var issues []ValidationIssue
+	for _, trigger := range p.config.Triggers {
+		if _, ok := registry.Lookup(trigger.Pipeline); !ok {
+			issues = append(issues, ValidationIssue{Param: -1,
+				Message: fmt.Sprintf("triggered pipeline %q is not registered", trigger.Pipeline)})
+		}
+		for _, name := range trigger.Steps {
+			if stepPosition(p.steps, name) < 0 {
+				issues = append(issues, ValidationIssue{Param: -1,
+					Message: fmt.Sprintf("trigger of pipeline %q passes outputs of unknown step %q", trigger.Pipeline, name)})
+			}
+		}
+	}
+	if name, ok := registry.nameOf(p); ok {
+		registry.mu.RLock()
+		cycle := registry.cycleFrom(name)
+		registry.mu.RUnlock()
+		if cycle != nil {
+			issues = append(issues, ValidationIssue{Param: -1,
+				Message: fmt.Sprintf("%s: %s", ErrChainCycle, strings.Join(cycle, " -> "))})
+		}
+	}
+	return issues
+}
+
+// triggerInputs returns the outputs of the steps of trigger, leaving out their
+// error results: those are nil once the run succeeded, and nil is no input. Each
+// output is passed under its result type, as TypedInput would, so that a nil
+// interface result such as an io.Reader is still an input of that type.
+func (p *Pipeline) triggerInputs(trigger Trigger) []interface{} {
+	var inputs []interface{}
+	for _, name := range trigger.Steps {
+		outputs := p.stepOutputs[name]
+		var fnType reflect.Type
+		if i := stepPosition(p.steps, name); i >= 0 {
+			if fnType = reflect.TypeOf(p.steps[i].Callable); fnType != nil && fnType.Kind() != reflect.Func {
+				fnType = nil
+			}
+		}
+		if fnType != nil {
+			if n := fnType.NumOut(); n > 0 && fnType.Out(n-1) == errorType && len(outputs) >= n {
+				outputs = outputs[:n-1]
+			}
+		}
+		for i, out := range outputs {
+			if fnType == nil || i >= fnType.NumOut() {
+				inputs = append(inputs, out)
+				continue
+			}
+			val := reflect.New(fnType.Out(i)).Elem()
+			if out != nil {
+				val.Set(reflect.ValueOf(out))
+			}
+			inputs = append(inputs, typedInput{val: val})
+		}
+	}
+	return inputs
+}
+
+type chainKey struct{}
+
+// chainLink describes, in the context of a triggered run, the runs that led to it.
+type chainLink struct {
+	root      string   // run ID of the first run of the chain
+	parent    string   // run ID of the run that triggered this one
+	pipelines []string // registry names of the pipelines run so far, first to last
+}
+
+func chainFrom(ctx context.Context) (chainLink, bool) {
+	link, ok := ctx.Value(chainKey{}).(chainLink)
+	return link, ok
+}
+
+// trigger starts the runs of the pipelines triggered by a successful run, one after
+// the other. Their failures are logged and recorded in result, never returned.
+func (p *Pipeline) trigger(ctx context.Context, result *Result) {
+	if len(p.config.Triggers) == 0 || p.partial != nil {
+		return
+	}
+	registry := p.config.Pipelines
+	link, chained := chainFrom(ctx)
+	if !chained {
+		link.root = result.RunID
+		if registry != nil {
+			if name, ok := registry.nameOf(p); ok {
+				link.pipelines = []string{name}
+			}
+		}
+	}
+	link.parent = result.RunID
+
+	for _, trigger := range p.config.Triggers {
+		run := TriggeredRun{Pipeline: trigger.Pipeline}
+		inputs := p.triggerInputs(trigger)
+		var next *Pipeline
+		ok := false
+		if registry != nil {
+			next, ok = registry.Lookup(trigger.Pipeline)
+		}
+		attempts := 0
+		switch {
+		case !ok:
+			run.Err = fmt.Errorf("triggered pipeline %s: not registered", trigger.Pipeline)
+		case slices.Contains(link.pipelines, trigger.Pipeline):
+			// Never dead-lettered: running it again cannot succeed.
+			run.Err = fmt.Errorf("%w: %s -> %s", ErrChainCycle, strings.Join(link.pipelines, " -> "), trigger.Pipeline)
+		default:
+			nextLink := link
+			nextLink.pipelines = append(slices.Clone(link.pipelines), trigger.Pipeline)
+			for attempts <= trigger.Retries {
+				if attempts > 0 {
+					p.logger.Warnf("Retrying triggered pipeline %q after: %v", trigger.Pipeline, run.Err)
+				}
+				attempts++
+				runCtx := context.WithValue(WithRunID(ctx, NewRunID()), chainKey{}, nextLink)
+				p.logger.Infof("Triggering pipeline %q", trigger.Pipeline)
+				var triggered *Result
+				triggered, run.Err = next.Run(runCtx, inputs...)
+				run.RunID = triggered.RunID
+				if run.Err == nil {
+					break
+				}
+			}
+		}
+		if run.Err != nil {
+			p.logger.Errorf("Triggered pipeline %q failed: %v", trigger.Pipeline, run.Err)
+			if !errors.Is(run.Err, ErrChainCycle) || attempts > 0 {
+				p.deadLetter(trigger, link, inputs, attempts, run.Err)
+			}
+		}
+		result.Triggered = append(result.Triggered, run)
+	}
+}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestTriggerFromStepReturningError(t *testing.T) {
+	registry := NewPipelineRegistry()
+	cfg := NewPipelineConfig()
+	cfg.Pipelines = registry
+	cfg.Triggers = []Trigger{{Pipeline: "next", Steps: []string{"extract"}}}
+	first := NewPipeline(cfg, nil)
+	first.AddStep("extract", func() (int, error) { return 42, nil })
+
+	var got int
+	next := NewPipeline(nil, nil)
+	next.AddStep("load", func(n int) { got = n })
+	if err := registry.Register("first", first); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("next", next); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := first.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Triggered) != 1 || result.Triggered[0].Err != nil {
+		t.Fatalf("Triggered = %+v, want one successful run", result.Triggered)
+	}
+	if got != 42 {
+		t.Errorf("triggered pipeline got %d, want 42", got)
+	}
+}
+
+func TestTriggerFromStepReturningNilInterface(t *testing.T) {
+	registry := NewPipelineRegistry()
+	cfg := NewPipelineConfig()
+	cfg.Pipelines = registry
+	cfg.Triggers = []Trigger{{Pipeline: "next", Steps: []string{"open"}}}
+	first := NewPipeline(cfg, nil)
+	first.AddStep("open", func() (io.Reader, error) { return nil, nil })
+
+	called := false
+	next := NewPipeline(nil, nil)
+	next.AddStep("read", func(r io.Reader) {
+		called = true
+		if r != nil {
+			t.Errorf("read got %v, want a nil reader", r)
+		}
+	})
+	if err := registry.Register("first", first); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("next", next); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := first.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Triggered) != 1 || result.Triggered[0].Err != nil {
+		t.Fatalf("Triggered = %+v, want one successful run", result.Triggered)
+	}
+	if !called {
+		t.Error("triggered pipeline did not run its step")
+	}
+}
@@ -0,0 +1,213 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+// Checkpoint is the persisted state of a step that completed successfully.
+type Checkpoint struct {
+	Step    string            `json:"step"`
+	Outputs []json.RawMessage `json:"outputs"`
+	Types   []string          `json:"types,omitempty"` // Names of the output types, see PipelineConfig.TypeRegistry.
+}
+
+// CheckpointStore persists checkpoints of a pipeline run under a key.
+type CheckpointStore interface {
+	// Save appends the checkpoint of a completed step.
+	Save(key string, cp Checkpoint) error
+	// Load returns the checkpoints saved under key, in completion order.
+	Load(key string) ([]Checkpoint, error)
+	// Clear removes every checkpoint saved under key.
+	Clear(key string) error
+}
+
+// FileCheckpointStore keeps one JSON file per key in a directory.
+type FileCheckpointStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("checkpoint store: %w", err)
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+func (s *FileCheckpointStore) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key)+".json")
+}
+
+func (s *FileCheckpointStore) Save(key string, cp Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cps, err := s.load(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(append(cps, cp), "", "  ")
+	if err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	// Write to a temporary file first so a crash never leaves a truncated checkpoint.
+	tmp := s.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(key)); err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	return nil
+}
+
+func (s *FileCheckpointStore) Load(key string) ([]Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(key)
+}
+
+func (s *FileCheckpointStore) load(key string) ([]Checkpoint, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint store: %w", err)
+	}
+	var cps []Checkpoint
+	if err := json.Unmarshal(data, &cps); err != nil {
+		return nil, fmt.Errorf("checkpoint store: corrupt checkpoint %s: %w", s.path(key), err)
+	}
+	return cps, nil
+}
+
+func (s *FileCheckpointStore) Clear(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	return nil
+}
+
+// checkpointKey is the key under which this pipeline's checkpoints are stored.
+func (p *Pipeline) checkpointKey() string {
+	if p.config.CheckpointKey != "" {
+		return p.config.CheckpointKey
+	}
+	return p.config.Name
+}
+
+// loadCheckpoints returns the checkpoints to resume from, keyed by step name.
+func (p *Pipeline) loadCheckpoints() (map[string]Checkpoint, error) {
+	if p.config.CheckpointStore == nil || (!p.config.Resume && p.partial == nil) {
+		return nil, nil
+	}
+	cps, err := p.config.CheckpointStore.Load(p.checkpointKey())
+	if err != nil {
+		return nil, err
+	}
+	byStep := make(map[string]Checkpoint, len(cps))
+	for _, cp := range cps {
+		// Without Resume, partial runs restore only the steps upstream of theirs.
+		if p.config.Resume || p.upstream(cp.Step) {
+			byStep[cp.Step] = cp
+		}
+	}
+	if len(byStep) > 0 {
+		p.logger.Infof("Resuming from %d checkpointed step(s)", len(byStep))
+	}
+	return byStep, nil
+}
+
+func (p *Pipeline) saveCheckpoint(step Step, outputs []interface{}) error {
+	// Outputs of streaming and StepRunner steps cannot be restored from JSON.
+	if p.config.CheckpointStore == nil || isRunner(step) || isStreamingStep(reflect.TypeOf(step.Callable)) {
+		return nil
+	}
+	cp := Checkpoint{Step: step.Name}
+	fnType := reflect.TypeOf(step.Callable)
+	for i, out := range outputs {
+		typeName := p.config.TypeRegistry.Name(fnType.Out(i))
+		if fnType.Out(i).Kind() == reflect.Interface && out != nil {
+			// JSON keeps no type: the value is restored as its registered dynamic type.
+			name, ok := p.config.TypeRegistry.registeredName(reflect.TypeOf(out))
+			if !ok {
+				return fmt.Errorf("cannot checkpoint output %d of interface type %s: its dynamic type %T is not registered in PipelineConfig.TypeRegistry",
+					i, fnType.Out(i), out)
+			}
+			typeName = name
+		}
+		data, err := json.Marshal(out)
+		if err != nil {
+			return fmt.Errorf("cannot checkpoint output %d: %w", i, err)
+		}
+		cp.Outputs = append(cp.Outputs, data)
+		cp.Types = append(cp.Types, typeName)
+	}
+	return p.config.CheckpointStore.Save(p.checkpointKey(), cp)
+}
+
+// restoreCheckpoint decodes a step's checkpointed outputs into its declared output
+// types and stores them as if the step had just run.
+func (p *Pipeline) restoreCheckpoint(step Step, cp Checkpoint) ([]interface{}, error) {
+	fnType := reflect.TypeOf(step.Callable)
+	if len(cp.Outputs) != fnType.NumOut() {
+		return nil, fmt.Errorf("checkpoint has %d outputs but the step returns %d",
+			len(cp.Outputs), fnType.NumOut())
+	}
+	registry := p.config.TypeRegistry
+	if registry != nil && len(cp.Types) == len(cp.Outputs) {
+		// Without a registry, outputs are restored by structure whatever their type was.
+		for i, name := range cp.Types {
+			if fnType.Out(i).Kind() == reflect.Interface {
+				continue // checked against its dynamic type below
+			}
+			if !registry.matches(name, fnType.Out(i)) {
+				return nil, fmt.Errorf("checkpoint output %d has type %s, but the step now returns %s",
+					i, name, registry.Name(fnType.Out(i)))
+			}
+		}
+	}
+	results := make([]reflect.Value, len(cp.Outputs))
+	for i, raw := range cp.Outputs {
+		outType := fnType.Out(i)
+		decodeType := outType
+		if outType.Kind() == reflect.Interface && string(raw) != "null" {
+			var t reflect.Type
+			ok := registry != nil && i < len(cp.Types)
+			if ok {
+				t, ok = registry.Lookup(cp.Types[i])
+			}
+			if !ok {
+				return nil, fmt.Errorf("cannot restore output %d of interface type %s: its dynamic type is not registered in PipelineConfig.TypeRegistry",
+					i, outType)
+			}
+			if !t.AssignableTo(outType) {
+				return nil, fmt.Errorf("checkpoint output %d has type %s, which does not implement %s", i, cp.Types[i], outType)
+			}
+			decodeType = t
+		}
+		ptr := reflect.New(decodeType)
+		if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("cannot restore output %d as %s: %w", i, decodeType, err)
+		}
+		results[i] = reflect.New(outType).Elem()
+		results[i].Set(ptr.Elem())
+	}
+	p.context.StoreResults(results)
+
+	outputs := make([]interface{}, len(results))
+	for i, r := range results {
+		outputs[i] = r.Interface()
+	}
+	p.stepOutputs[step.Name] = append(p.stepOutputs[step.Name], outputs...)
+	return outputs, nil
+}
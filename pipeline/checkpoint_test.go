@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	store, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewPipelineConfig()
+	cfg.Name = "resume"
+	cfg.CheckpointStore = store
+	cfg.Resume = true
+	p := NewPipeline(cfg, discardLogger)
+	extracts := 0
+	p.AddStep("extract", func() (int, string) { extracts++; return 42, "rows" })
+	errBoom := errors.New("boom")
+	failing := true
+	var got int
+	p.AddStep("load", func(n int, s string) error {
+		if failing {
+			return errBoom
+		}
+		got = n
+		return nil
+	})
+
+	if _, err := p.Run(context.Background()); !errors.Is(err, errBoom) {
+		t.Fatalf("first Run = %v, want %v", err, errBoom)
+	}
+	failing = false
+	result, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if extracts != 1 {
+		t.Errorf("extract ran %d times, want 1", extracts)
+	}
+	if !result.Steps[0].Resumed {
+		t.Errorf("record of extract = %+v, want it resumed", result.Steps[0])
+	}
+	if got != 42 {
+		t.Errorf("load got %d, want the checkpointed 42", got)
+	}
+
+	// The successful run cleared the checkpoints: the next run starts over.
+	if _, err := p.Run(context.Background()); err != nil {
+		t.Fatalf("third Run: %v", err)
+	}
+	if extracts != 2 {
+		t.Errorf("extract ran %d times after a successful run, want 2", extracts)
+	}
+}
+
+type shape interface{ Area() float64 }
+
+type square struct{ Side float64 }
+
+func (s square) Area() float64 { return s.Side * s.Side }
+
+// interfacePipeline returns a pipeline whose step "measure" returns a shape and
+// fails its next step on the first run.
+func interfacePipeline(t *testing.T, types *TypeRegistry, fail *bool, got *shape) *Pipeline {
+	store, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewPipelineConfig()
+	cfg.Name = "shapes"
+	cfg.CheckpointStore = store
+	cfg.Resume = true
+	cfg.TypeRegistry = types
+	p := NewPipeline(cfg, discardLogger)
+	p.AddStep("measure", func() shape { return square{Side: 2} })
+	p.AddStep("use", func(s shape) error {
+		if *fail {
+			return errors.New("boom")
+		}
+		*got = s
+		return nil
+	})
+	return p
+}
+
+func TestCheckpointInterfaceOutput(t *testing.T) {
+	types := NewTypeRegistry()
+	if err := types.Register("square", square{}); err != nil {
+		t.Fatal(err)
+	}
+	fail := true
+	var got shape
+	p := interfacePipeline(t, types, &fail, &got)
+	if _, err := p.Run(context.Background()); err == nil {
+		t.Fatal("first Run succeeded, want the failure of use")
+	}
+	fail = false
+	result, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if !result.Steps[0].Resumed {
+		t.Errorf("record of measure = %+v, want it resumed", result.Steps[0])
+	}
+	if got != (square{Side: 2}) {
+		t.Errorf("use got %#v, want square{Side: 2}", got)
+	}
+}
+
+func TestCheckpointInterfaceOutputNeedsRegisteredType(t *testing.T) {
+	fail := false
+	var got shape
+	p := interfacePipeline(t, nil, &fail, &got)
+	result, err := p.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "not registered in PipelineConfig.TypeRegistry") {
+		t.Fatalf("Run = %v, want an error about the unregistered type", err)
+	}
+	if result.Steps[0].Err == nil {
+		t.Error("measure succeeded, want it failed on saving its checkpoint")
+	}
+}
@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Clone returns a copy of the pipeline's steps, teardown and assert steps,
+// preflight checks, observers and configuration, with an empty execution context of its own, so
+// that a template pipeline can be customized per job without affecting it. The
+// initial inputs are copied only if withInputs is true. Values behind interfaces
+// and pointers in the configuration (loggers, stores, caches, circuit breakers,
+// literal values) are shared with p.
+func (p *Pipeline) Clone(withInputs bool) *Pipeline {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	clone := &Pipeline{
+		steps:        slices.Clone(p.steps),
+		context:      NewExecutionContext(),
+		config:       p.config.Clone(),
+		logger:       p.logger,
+		stepOutputs:  make(map[string][]interface{}),
+		pickCounters: make(map[reflect.Type]int),
+		observers:    slices.Clone(p.observers),
+		progressFns:  slices.Clone(p.progressFns),
+		startHooks:   slices.Clone(p.startHooks),
+		finishHooks:  slices.Clone(p.finishHooks),
+
+		teardownSteps:   slices.Clone(p.teardownSteps),
+		assertSteps:     slices.Clone(p.assertSteps),
+		preflightChecks: slices.Clone(p.preflightChecks),
+		disabledSteps:   maps.Clone(p.disabledSteps),
+	}
+	if withInputs {
+		clone.context.copyInputs(p.context)
+	}
+	return clone
+}
+
+// Clone returns a deep copy of the configuration's slices, step configs and
+// argument bindings.
+func (c *PipelineConfig) Clone() *PipelineConfig {
+	clone := *c
+	clone.StepOrder = slices.Clone(c.StepOrder)
+	clone.OutputFilter = slices.Clone(c.OutputFilter)
+	clone.IncludeTags = slices.Clone(c.IncludeTags)
+	clone.ExcludeTags = slices.Clone(c.ExcludeTags)
+	clone.Notifiers = slices.Clone(c.Notifiers)
+	clone.Triggers = slices.Clone(c.Triggers)
+	for i, trigger := range clone.Triggers {
+		clone.Triggers[i].Steps = slices.Clone(trigger.Steps)
+	}
+	clone.StepConfigs = make(map[string]*StepConfig, len(c.StepConfigs))
+	for name, stepCfg := range c.StepConfigs {
+		if stepCfg == nil {
+			clone.StepConfigs[name] = nil
+			continue
+		}
+		clone.StepConfigs[name] = stepCfg.Clone()
+	}
+	return &clone
+}
+
+// Clone returns a copy of the step config with its own bindings and output names.
+func (c *StepConfig) Clone() *StepConfig {
+	clone := *c
+	clone.OutputNames = slices.Clone(c.OutputNames)
+	clone.Tags = slices.Clone(c.Tags)
+	if c.WaitFor != nil {
+		cond := *c.WaitFor
+		clone.WaitFor = &cond
+	}
+	if c.ArgBindings != nil {
+		clone.ArgBindings = make([]*ArgBinding, len(c.ArgBindings))
+		for i, binding := range c.ArgBindings {
+			if binding == nil {
+				continue
+			}
+			b := *binding
+			b.Names = slices.Clone(binding.Names)
+			clone.ArgBindings[i] = &b
+		}
+	}
+	return &clone
+}
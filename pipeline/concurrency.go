@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrConcurrencyGroupBusy is returned when a run is rejected because another run of its group is active.
+var ErrConcurrencyGroupBusy = errors.New("concurrency group busy")
+
+type ConcurrencyPolicy int
+
+const (
+	// ConcurrencyPolicyQueue waits until the group is free, or the run is cancelled
+	// or reaches its Deadline or MaxDuration.
+	ConcurrencyPolicyQueue ConcurrencyPolicy = iota
+	// ConcurrencyPolicyReject fails immediately with ErrConcurrencyGroupBusy.
+	ConcurrencyPolicyReject
+)
+
+// GroupLocker grants exclusive access to a named concurrency group. Implementations
+// backed by a distributed lock allow groups to be enforced across processes.
+type GroupLocker interface {
+	// Lock blocks until the group is acquired, or fails with ctx's error once ctx is done.
+	Lock(ctx context.Context, group string) (unlock func(), err error)
+	// TryLock acquires the group only if it is free.
+	TryLock(group string) (unlock func(), ok bool, err error)
+}
+
+var globalGroupLocker GroupLocker = newLocalGroupLocker() // process-local unless overridden by user.
+
+// globalSerialGroupLocker holds step serial groups, apart from pipeline concurrency
+// groups so that no group name of one can collide with the other.
+var globalSerialGroupLocker GroupLocker = newLocalGroupLocker()
+
+// SetGroupLocker changes the package-wide locker used to enforce concurrency groups.
+func SetGroupLocker(l GroupLocker) {
+	if l != nil {
+		globalGroupLocker = l
+	}
+}
+
+// SetSerialGroupLocker changes the package-wide locker used to enforce
+// StepConfig.SerialGroup. It must not be the GroupLocker given to SetGroupLocker
+// unless its groups are namespaced, since a serial group and a concurrency group
+// may share a name.
+func SetSerialGroupLocker(l GroupLocker) {
+	if l != nil {
+		globalSerialGroupLocker = l
+	}
+}
+
+type localGroupLocker struct {
+	mu     sync.Mutex
+	groups map[string]chan struct{}
+}
+
+func newLocalGroupLocker() *localGroupLocker {
+	return &localGroupLocker{groups: make(map[string]chan struct{})}
+}
+
+func (l *localGroupLocker) slot(group string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ch, ok := l.groups[group]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		l.groups[group] = ch
+	}
+	return ch
+}
+
+func (l *localGroupLocker) Lock(ctx context.Context, group string) (func(), error) {
+	ch := l.slot(group)
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *localGroupLocker) TryLock(group string) (func(), bool, error) {
+	ch := l.slot(group)
+	select {
+	case ch <- struct{}{}:
+		return func() { <-ch }, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// acquireSerialGroup waits for the StepConfig.SerialGroup of step, if it has one.
+func (p *Pipeline) acquireSerialGroup(step Step) (func(), error) {
+	stepCfg, ok := p.config.StepConfigs[step.Name]
+	if !ok || stepCfg.SerialGroup == "" {
+		return func() {}, nil
+	}
+	ctx := p.runCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	unlock, err := globalSerialGroupLocker.Lock(ctx, stepCfg.SerialGroup)
+	if err != nil {
+		return nil, fmt.Errorf("serial group %s: %w", stepCfg.SerialGroup, err)
+	}
+	return unlock, nil
+}
+
+// acquireConcurrencyGroup enters config.ConcurrencyGroup (if any) according to
+// config.ConcurrencyPolicy, waiting no longer than ctx allows.
+func (p *Pipeline) acquireConcurrencyGroup(ctx context.Context) (func(), error) {
+	group := p.config.ConcurrencyGroup
+	if group == "" {
+		return func() {}, nil
+	}
+
+	switch p.config.ConcurrencyPolicy {
+	case ConcurrencyPolicyReject:
+		unlock, ok, err := globalGroupLocker.TryLock(group)
+		if err != nil {
+			return nil, fmt.Errorf("concurrency group %s: %w", group, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("concurrency group %s: %w", group, ErrConcurrencyGroupBusy)
+		}
+		return unlock, nil
+	default:
+		p.logger.Debugf("Waiting for concurrency group %q", group)
+		unlock, err := globalGroupLocker.Lock(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("concurrency group %s: %w", group, err)
+		}
+		return unlock, nil
+	}
+}
@@ -0,0 +1,51 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSerialGroupApartFromConcurrencyGroups(t *testing.T) {
+	// A run holding the concurrency group "serial:db" must not block steps of the
+	// serial group "db".
+	unlock, err := globalGroupLocker.Lock(context.Background(), "serial:db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	cfg := NewPipelineConfig()
+	cfg.StepConfigs["write"] = &StepConfig{SerialGroup: "db"}
+	p := NewPipeline(cfg, discardLogger)
+	p.AddStep("write", func() {})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := p.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestSerialGroupSerializesRuns(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.StepConfigs["write"] = &StepConfig{SerialGroup: "serialized"}
+	p := NewPipeline(cfg, discardLogger)
+	var c concurrency
+	p.AddStep("write", func() { c.enter() })
+
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := p.Run(context.Background())
+			errs <- err
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+	if got := c.max.Load(); got != 1 {
+		t.Errorf("%d steps of the serial group ran at once, want 1", got)
+	}
+}
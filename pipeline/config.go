@@ -1,10 +1,36 @@
 package pipeline
 
+import "time"
+
 type MissingArgPolicy int
 
 const (
 	MissingArgPolicyUseLatest MissingArgPolicy = iota
 	MissingArgPolicyFail
+	// MissingArgPolicyZeroValue behaves like MissingArgPolicyUseLatest but passes
+	// the type's zero value when no value is available.
+	MissingArgPolicyZeroValue
+)
+
+type ErrorPolicy int
+
+const (
+	// ErrorPolicyFailFast stops the pipeline at the first failing step.
+	ErrorPolicyFailFast ErrorPolicy = iota
+	// ErrorPolicyContinueCollect keeps running the remaining steps and reports every failure at the end.
+	ErrorPolicyContinueCollect
+)
+
+// Severity decides how a step's failure affects the run.
+type Severity int
+
+const (
+	// SeverityDefault follows PipelineConfig.ErrorPolicy.
+	SeverityDefault Severity = iota
+	// SeverityCritical stops the pipeline, whatever the ErrorPolicy.
+	SeverityCritical
+	// SeverityBestEffort logs a warning and carries on; the run does not fail.
+	SeverityBestEffort
 )
 
 type ArgSourceType int
@@ -13,32 +39,218 @@ const (
 	ArgSourceDefault ArgSourceType = iota
 	ArgSourceInitial
 	ArgSourceFunctionOutput
+	ArgSourceLiteral
+	ArgSourceEnv
+	ArgSourceCollect
+	ArgSourceNamedInput
 )
 
 type ArgBinding struct {
 	Source ArgSourceType
-	Name   string // Step name if Source = ArgSourceFunctionOutput.
+	Name   string // Step name for ArgSourceFunctionOutput, variable name for ArgSourceEnv, input name for ArgSourceNamedInput.
 	Index  int    // Index in the initial inputs or in a function’s outputs.
+
+	// OutputName selects a function output by the name declared in the
+	// producing step's StepConfig.OutputNames. It takes precedence over Index.
+	OutputName string
+
+	// Names restricts ArgSourceCollect to the outputs of these steps. When empty,
+	// every value of the slice's element type stored so far is collected.
+	Names []string
+
+	// Value is the constant passed to the parameter if Source = ArgSourceLiteral,
+	// or the default used when the variable is unset if Source = ArgSourceEnv.
+	Value interface{}
+
+	// Default, if set, is passed to the parameter when the binding finds no value,
+	// such as when its step is disabled, was skipped or produced no outputs, instead
+	// of failing the step. Errors other than CodeArgNotFound, like a value of the
+	// wrong type, still fail it.
+	Default interface{}
 }
 
 type StepConfig struct {
 	ArgBindings []*ArgBinding
+
+	// Description and Tags document the step; see WithDescription and WithTags.
+	// PipelineConfig.IncludeTags and ExcludeTags select steps by their tags.
+	Description string
+	Tags        []string
+
+	// OutputNames assigns a name to each of the step's return values, by position.
+	OutputNames []string
+
+	// Severity decides whether the step's failure stops the run, fails it at the
+	// end, or is only logged.
+	Severity Severity
+
+	// Condition, if set, is evaluated right before the step runs; the step is
+	// skipped (and recorded as such in the Result) when it returns false.
+	Condition func(ctx *ExecutionContext) bool
+
+	// Disabled skips the step in every run, keeping its definition and bindings;
+	// it is recorded as skipped in the Result. See also Pipeline.DisableStep.
+	Disabled bool
+
+	// Memoize reuses the outputs cached in PipelineConfig.Cache for identical
+	// arguments instead of calling the step again. CacheTTL, if positive,
+	// overrides the cache's default expiry for this step's entries.
+	Memoize  bool
+	CacheTTL time.Duration
+
+	// CircuitBreaker, if set, guards calls to the step across runs.
+	CircuitBreaker *CircuitBreaker
+
+	// Parallelism declares how many CPUs the step uses internally. The value is
+	// injected into Parallelism parameters, and that many CPUs are reserved from a
+	// process-wide budget of GOMAXPROCS while the step runs.
+	Parallelism int
+
+	// Logger, if set, replaces the pipeline's logger while the step runs.
+	Logger Logger
+
+	// Lazy defers the step until a later step requests its outputs through an
+	// ArgSourceFunctionOutput or ArgSourceCollect binding naming it. A lazy step
+	// that is never requested does not run and is recorded as skipped.
+	Lazy bool
+
+	// StreamBuffer is the capacity of the channels created for a streaming step
+	// (one with chan<- T parameters); zero makes them unbuffered.
+	StreamBuffer int
+
+	// SerialGroup, if set, keeps the step from being called while another step of
+	// the same group is, such as steps writing to the same SQLite file. AutoParallel
+	// never batches them together, and the group is held through the serial group
+	// locker (see SetSerialGroupLocker) while the step is called, so concurrent runs
+	// honour it too. Serial groups never collide with ConcurrencyGroup names.
+	SerialGroup string
+
+	// WaitFor, if set, holds the step until an external condition holds, such as a
+	// file existing (see FileExists), before its arguments are resolved.
+	WaitFor *WaitCondition
+
+	// HeartbeatInterval overrides PipelineConfig.HeartbeatInterval for the step; a
+	// negative value disables its heartbeats.
+	HeartbeatInterval time.Duration
 }
 
 type PipelineConfig struct {
+	// Name identifies the pipeline in logs and metrics.
+	Name string
+
 	// StepOrder is a list of step names indicating the desired order.
 	// Steps not listed appear afterward in their original order.
 	StepOrder []string
 
 	MissingArgPolicy MissingArgPolicy
-	OutputFilter     []string
-	StepConfigs      map[string]*StepConfig
+	ErrorPolicy      ErrorPolicy
+
+	// OutputFilter restricts Result.Outputs to the steps it matches. Entries are
+	// exact step names, glob patterns such as "extract-*", or regular expressions
+	// prefixed with "re:", such as "re:^extract-[0-9]+$". A step whose name equals
+	// an entry always matches it. Globs follow path.Match, where * and ? do not
+	// match '/': use a regular expression to select step names containing '/'.
+	OutputFilter []string
+	StepConfigs  map[string]*StepConfig
+
+	// IncludeTags, if set, runs only the main steps tagged with one of them, such as
+	// the "fast" steps in CI smoke tests; ExcludeTags leaves out the main steps
+	// tagged with one of them. Steps left out are skipped like disabled steps, and
+	// bindings to their outputs fail as they would for a disabled step.
+	IncludeTags []string
+	ExcludeTags []string
+
+	// ConcurrencyGroup, if set, allows only one run across all pipelines sharing
+	// the group name at a time. ConcurrencyPolicy decides what happens to the others.
+	ConcurrencyGroup  string
+	ConcurrencyPolicy ConcurrencyPolicy
+
+	// CheckpointStore, if set, receives the outputs of every completed step. The
+	// checkpoints are kept under CheckpointKey (Name if empty) until a run succeeds.
+	CheckpointStore CheckpointStore
+	CheckpointKey   string
+	// Resume skips steps found in the checkpoint store and restores their outputs.
+	Resume bool
+
+	// Cache stores the outputs of steps with StepConfig.Memoize set, keyed by
+	// the hash of their arguments computed by Hasher (JSONHasher if nil). Types
+	// JSON cannot hash faithfully can implement HashKeyer instead.
+	Cache  StepCache
+	Hasher Hasher
+
+	// AllowConversion lets arguments be bound from values of a different type that
+	// converts to the parameter type without loss, such as int32 to int64 or a
+	// string to a named string type. Default resolution only falls back to
+	// conversion when no value of the exact type exists.
+	AllowConversion bool
+
+	// Converters, if set, feeds parameters for which no value of their type exists
+	// through adapter steps converting a value of another type; see ConverterRegistry.
+	Converters *ConverterRegistry
+
+	// AutoParallel runs consecutive steps that do not consume each other's outputs
+	// concurrently. Dependencies are inferred from step signatures and bindings as
+	// Validate resolves them; arguments are resolved and outputs stored in step
+	// order, so later steps receive the same values as in a sequential run. When a
+	// step of such a batch fails, the other steps of the batch still complete.
+	AutoParallel bool
+
+	// Workers, if positive, bounds the goroutines working concurrently in the
+	// parallel regions of a run: the steps run together by AutoParallel, and the
+	// goroutines fan-out steps start besides the step's own. Each run has its own
+	// workers.
+	Workers int
+
+	// HeartbeatInterval, if positive, reports every step still executing after
+	// each interval, with the time elapsed, to tell hung steps from slow ones.
+	// Heartbeats are logged, or passed to OnHeartbeat if set, which is called from
+	// a goroutine of its own and must return quickly.
+	HeartbeatInterval time.Duration
+	OnHeartbeat       func(Heartbeat)
+
+	// Deadline and MaxDuration, if set, bound each run, from its start for
+	// MaxDuration: once the earlier of the two passes, the run's context is
+	// cancelled, no further main step starts and the run fails with a
+	// *DeadlineExceededError. Teardown steps still run.
+	Deadline    time.Time
+	MaxDuration time.Duration
+
+	// RunRegistry, if set, records the metadata of every run under a run ID.
+	RunRegistry *RunRegistry
+
+	// TypeRegistry, if set, names the types of checkpointed outputs and of the
+	// inputs recorded in RunRegistry, and checks the types of checkpoints on resume.
+	// A step output of an interface type can only be checkpointed if the dynamic
+	// type of its value is registered: it is restored as that type.
+	TypeRegistry *TypeRegistry
+
+	// Triggers start other pipelines of Pipelines, in order, when a run (other
+	// than a partial run such as RunOnly) succeeds, passing selected outputs as their
+	// initial inputs. Triggered runs finish before Run returns; their failures do
+	// not fail the run but are recorded in Result.Triggered. A pipeline is never
+	// triggered twice within a chain: such cycles fail with ErrChainCycle.
+	Triggers  []Trigger
+	Pipelines *PipelineRegistry
+	// DeadLetters, if set, keeps the inputs of triggered runs that still fail after
+	// their Trigger.Retries, to be resubmitted with PipelineRegistry.Resubmit.
+	DeadLetters DeadLetterStore
+
+	// Notifiers are told about every finished run, or only about failed runs if
+	// NotifyFailuresOnly is set.
+	Notifiers          []Notifier
+	NotifyFailuresOnly bool
+
+	// Audit chains the step records of every run into Result.Audit, a
+	// tamper-evident execution log checked by VerifyAudit. Arguments and outputs
+	// are hashed with Hasher (JSONHasher if nil).
+	Audit bool
 }
 
 func NewPipelineConfig() *PipelineConfig {
 	return &PipelineConfig{
 		StepOrder:        nil, // by default no reordering
 		MissingArgPolicy: MissingArgPolicyUseLatest,
+		ErrorPolicy:      ErrorPolicyFailFast,
 		OutputFilter:     nil, // Return outputs for all steps.
 		StepConfigs:      make(map[string]*StepConfig),
 	}
@@ -1,13 +1,35 @@
 package pipeline
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 )
 
+// ErrUntypedNil is reported for an initial input that is a nil interface{}: it has
+// no type, so no parameter could ever receive it. Pass a typed nil instead, such as
+// (*T)(nil) or TypedInput[io.Reader](nil).
+var ErrUntypedNil = errors.New("untyped nil has no type to be stored under")
+
 type ExecutionContext struct {
 	values        map[reflect.Type][]reflect.Value
 	initialValues []reflect.Value
+	stored        []reflect.Value // every value in storage order
+	inputNames    map[string]int  // index in initialValues of each named input
+	rejected      []error         // untyped nil inputs, reported when the pipeline runs
+}
+
+// typedInput carries an initial input together with the static type to store it under.
+type typedInput struct {
+	val reflect.Value
+}
+
+// TypedInput wraps an initial input so that it is stored under its static type T
+// rather than its dynamic type. It is the way to add a nil interface value, which
+// would otherwise be an untyped nil: TypedInput[io.Reader](nil) is received by
+// io.Reader parameters as a nil reader.
+func TypedInput[T any](value T) interface{} {
+	return typedInput{val: reflect.ValueOf(&value).Elem()}
 }
 
 func NewExecutionContext() *ExecutionContext {
@@ -19,17 +41,73 @@ func NewExecutionContext() *ExecutionContext {
 }
 
 func (ctx *ExecutionContext) AddInputs(inputs ...interface{}) {
-	// stores initial inputs in the context.
+	// stores initial inputs in the context, rejecting untyped nils.
 	for _, in := range inputs {
-		val := reflect.ValueOf(in)
-		t := val.Type()
-		ctx.values[t] = append(ctx.values[t], val)
-		ctx.initialValues = append(ctx.initialValues, val)
+		if in == nil {
+			ctx.rejected = append(ctx.rejected,
+				fmt.Errorf("initial input %d: %w", len(ctx.initialValues)+len(ctx.rejected), ErrUntypedNil))
+			continue
+		}
+		ctx.addInput(inputValue(in))
+	}
+}
+
+func (ctx *ExecutionContext) addInput(val reflect.Value) {
+	ctx.initialValues = append(ctx.initialValues, val)
+	ctx.storeValue(val)
+}
+
+// inputValue returns the value of an initial input, unwrapping TypedInput.
+func inputValue(in interface{}) reflect.Value {
+	if typed, ok := in.(typedInput); ok {
+		return typed.val
+	}
+	return reflect.ValueOf(in)
+}
+
+func (ctx *ExecutionContext) AddNamedInput(name string, value interface{}) {
+	// stores an initial input that bindings can also reference by name.
+	if value == nil {
+		ctx.rejected = append(ctx.rejected, fmt.Errorf("initial input %q: %w", name, ErrUntypedNil))
+		return
+	}
+	ctx.addInput(inputValue(value))
+	if ctx.inputNames == nil {
+		ctx.inputNames = make(map[string]int)
 	}
+	ctx.inputNames[name] = len(ctx.initialValues) - 1
+}
+
+func (ctx *ExecutionContext) NamedInput(name string) (int, bool) {
+	// returns the index among the initial inputs of the input named name.
+	index, ok := ctx.inputNames[name]
+	return index, ok
+}
+
+func (ctx *ExecutionContext) copyInputs(from *ExecutionContext) {
+	// adds the initial inputs of another context, keeping their names.
+	offset := len(ctx.initialValues)
+	for _, v := range from.initialValues {
+		ctx.addInput(v)
+	}
+	ctx.rejected = append(ctx.rejected, from.rejected...)
+	for name, index := range from.inputNames {
+		if ctx.inputNames == nil {
+			ctx.inputNames = make(map[string]int)
+		}
+		ctx.inputNames[name] = offset + index
+	}
+}
+
+func (ctx *ExecutionContext) inputError() error {
+	// returns the rejected initial inputs, if any.
+	return errors.Join(ctx.rejected...)
 }
 
 func (ctx *ExecutionContext) StoreResults(results []reflect.Value) {
-	// adds new result values to the context.
+	// adds new result values to the context. Values are kept as returned, never copied:
+	// a []byte shares its backing array with every consumer and an io.Reader is the
+	// same reader for all of them (Validate warns when one is bound more than once).
 	for _, result := range results {
 		ctx.storeValue(result)
 	}
@@ -45,10 +123,26 @@ func (ctx *ExecutionContext) InitialValues() []reflect.Value {
 	return ctx.initialValues
 }
 
+func (ctx *ExecutionContext) Latest(t reflect.Type) (interface{}, bool) {
+	// returns the most recently stored value of type t.
+	vals := ctx.values[t]
+	if len(vals) == 0 {
+		return nil, false
+	}
+	return vals[len(vals)-1].Interface(), true
+}
+
+func (ctx *ExecutionContext) collect(sliceType reflect.Type) reflect.Value {
+	// gathers every stored value of the slice's element type into a new slice.
+	vals := ctx.values[sliceType.Elem()]
+	collected := reflect.MakeSlice(sliceType, 0, len(vals))
+	return reflect.Append(collected, vals...)
+}
+
 func (ctx *ExecutionContext) getValueByIndex(t reflect.Type, index int) (reflect.Value, error) {
-	// retrieves a value of type t at the specified index.
-	vals, ok := ctx.values[t]
-	if !ok || len(vals) == 0 {
+	// retrieves a value of type t, or implementing interface t, at the specified index.
+	vals := ctx.candidates(t)
+	if len(vals) == 0 {
 		return reflect.Value{}, fmt.Errorf("no values found for type %s", t)
 	}
 	if index < 0 {
@@ -64,4 +158,20 @@ func (ctx *ExecutionContext) storeValue(val reflect.Value) {
 	// appends a new value to the context by its type.
 	t := val.Type()
 	ctx.values[t] = append(ctx.values[t], val)
+	ctx.stored = append(ctx.stored, val)
+}
+
+func (ctx *ExecutionContext) candidates(t reflect.Type) []reflect.Value {
+	// returns the values stored under type t or, if there are none and t is an
+	// interface, every stored value implementing t, in storage order.
+	if vals := ctx.values[t]; len(vals) > 0 || t.Kind() != reflect.Interface {
+		return vals
+	}
+	var vals []reflect.Value
+	for _, val := range ctx.stored {
+		if val.Type().Implements(t) {
+			vals = append(vals, val)
+		}
+	}
+	return vals
 }
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// assignable reports whether a value of type from can be bound to a parameter of
+// type to, converting it when PipelineConfig.AllowConversion permits.
+func (p *Pipeline) assignable(from, to reflect.Type) bool {
+	return from.AssignableTo(to) || (p.config.AllowConversion && lossless(from, to))
+}
+
+// bindValue returns val as a value of type to; the caller checked assignable.
+func bindValue(val reflect.Value, to reflect.Type) reflect.Value {
+	if val.Type().AssignableTo(to) {
+		return val
+	}
+	return val.Convert(to)
+}
+
+// nilable reports whether nil is a valid value of type t.
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// lossless reports whether every value of type from converts to type to and back
+// unchanged: widening numeric conversions, named types sharing an underlying type,
+// and strings to and from byte or rune slices. Narrowing, sign-changing and
+// integer-to-string (rune) conversions are rejected even though Go allows them.
+func lossless(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+	fk, tk := from.Kind(), to.Kind()
+	switch {
+	case isIntKind(fk) && isIntKind(tk), isUintKind(fk) && isUintKind(tk), isFloatKind(fk) && isFloatKind(tk):
+		return to.Size() >= from.Size()
+	case isUintKind(fk) && isIntKind(tk):
+		return to.Size() > from.Size()
+	case (isIntKind(fk) || isUintKind(fk)) && isFloatKind(tk):
+		// float32 holds 24-bit and float64 53-bit integers exactly.
+		return (tk == reflect.Float32 && from.Size() <= 2) || (tk == reflect.Float64 && from.Size() <= 4)
+	case fk == reflect.String && tk == reflect.Slice, fk == reflect.Slice && tk == reflect.String:
+		return true
+	default:
+		// Named and unnamed types sharing an underlying type; this also rules out
+		// integer-to-string and the remaining numeric conversions.
+		return fk == tk
+	}
+}
+
+// convertibleSource finds the stored type whose values a parameter of type
+// paramType would receive by conversion, when none is stored under paramType. It
+// returns nil if there is none and an error if several types qualify.
+func convertibleSource[V any](paramType reflect.Type, values map[reflect.Type][]V) (reflect.Type, error) {
+	var found reflect.Type
+	for t, vals := range values {
+		if len(vals) == 0 || t == paramType || !lossless(t, paramType) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("values of types %s and %s both convert to %s", found, t, paramType)
+		}
+		found = t
+	}
+	return found, nil
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,180 @@
+package pipeline
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// ConverterRegistry holds user-supplied conversions between types. With
+// PipelineConfig.Converters, a parameter for which no value of its type exists is
+// fed by an adapter step inserted right before its step, which converts a value of
+// a type that does exist. Adapter steps run, appear in Result.Steps, Graph and
+// Explain like any other step.
+type ConverterRegistry struct {
+	mu    sync.RWMutex
+	funcs map[reflect.Type]map[reflect.Type]reflect.Value // by target, then source type
+}
+
+func NewConverterRegistry() *ConverterRegistry {
+	return &ConverterRegistry{funcs: make(map[reflect.Type]map[reflect.Type]reflect.Value)}
+}
+
+// Register adds a converter of the shape func(A) B or func(A) (B, error). Only one
+// converter may be registered from A to B.
+func (r *ConverterRegistry) Register(fn interface{}) error {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func || fnType.NumIn() != 1 ||
+		!(fnType.NumOut() == 1 || fnType.NumOut() == 2 && fnType.Out(1) == errorType) {
+		return fmt.Errorf("converter must have the shape func(A) B or func(A) (B, error), got %v", fnType)
+	}
+	from, to := fnType.In(0), fnType.Out(0)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.funcs[to][from]; exists {
+		return fmt.Errorf("converter from %s to %s already registered", from, to)
+	}
+	if r.funcs[to] == nil {
+		r.funcs[to] = make(map[reflect.Type]reflect.Value)
+	}
+	r.funcs[to][from] = reflect.ValueOf(fn)
+	return nil
+}
+
+// clone returns a registry holding the same converters as r.
+func (r *ConverterRegistry) clone() *ConverterRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c := NewConverterRegistry()
+	for to, froms := range r.funcs {
+		c.funcs[to] = maps.Clone(froms)
+	}
+	return c
+}
+
+// converter returns the converter from type from to type to, if one is registered.
+func (r *ConverterRegistry) converter(from, to reflect.Type) (reflect.Value, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn, ok := r.funcs[to][from]
+	return fn, ok
+}
+
+// sources returns the converters to type to, by source type.
+func (r *ConverterRegistry) sources(to reflect.Type) map[reflect.Type]reflect.Value {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.funcs[to])
+}
+
+// RegisterConverter registers fn, of the shape func(A) B or func(A) (B, error), in
+// the pipeline's PipelineConfig.Converters, creating the registry if the pipeline
+// has none. Besides feeding adapter steps, converters then apply to bindings
+// (ArgSourceInitial, ArgSourceNamedInput, ArgSourceFunctionOutput) whose value is
+// of type A and parameter of type B. Every such conversion is recorded in the
+// step's StepRecord.Conversions.
+//
+// The converter is added to a copy of the registry, which then replaces
+// PipelineConfig.Converters: runs already started keep the converters they started
+// with, and a registry shared with other pipelines is left unchanged.
+func (p *Pipeline) RegisterConverter(fn interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	registry := NewConverterRegistry()
+	if p.config.Converters != nil {
+		registry = p.config.Converters.clone()
+	}
+	if err := registry.Register(fn); err != nil {
+		return err
+	}
+	p.config.Converters = registry
+	return nil
+}
+
+// Conversion records a bound value converted by a registered converter.
+type Conversion struct {
+	Param  int    `json:"param"`
+	Source string `json:"source"` // Where the value came from, such as "output 0 of step fetch".
+	From   string `json:"from"`   // Type of the value.
+	To     string `json:"to"`     // Type of the parameter.
+}
+
+// convertible reports whether a bound value of type from reaches a parameter of
+// type to, directly or through a registered converter.
+func (p *Pipeline) convertible(from, to reflect.Type) bool {
+	if p.assignable(from, to) {
+		return true
+	}
+	if p.config.Converters == nil {
+		return false
+	}
+	_, ok := p.config.Converters.converter(from, to)
+	return ok
+}
+
+// convertBound converts the bound value val from src to paramType with a
+// registered converter, if there is one, and records the conversion.
+func (p *Pipeline) convertBound(val reflect.Value, paramType reflect.Type, src valueRef) (reflect.Value, bool, error) {
+	if p.config.Converters == nil {
+		return reflect.Value{}, false, nil
+	}
+	fn, ok := p.config.Converters.converter(val.Type(), paramType)
+	if !ok {
+		return reflect.Value{}, false, nil
+	}
+	out := fn.Call([]reflect.Value{val})
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, true, fmt.Errorf("converting %s from %s to %s: %w", src, val.Type(), paramType, out[1].Interface().(error))
+	}
+	p.conversions = append(p.conversions, Conversion{Param: -1, Source: src.String(), From: val.Type().String(), To: paramType.String()})
+	p.logger.Debugf("Converted %s from %s to %s", src, val.Type(), paramType)
+	return out[0], true, nil
+}
+
+// adapterStep returns the step converting a value available in state to paramType
+// for the step named consumer, if exactly one registered converter applies.
+func (p *Pipeline) adapterStep(consumer string, paramType reflect.Type, state *typeState) (Step, bool, error) {
+	if p.config.Converters == nil {
+		return Step{}, false, nil
+	}
+	var adapter Step
+	var from reflect.Type
+	for source, fn := range p.config.Converters.sources(paramType) {
+		if len(state.candidates(source)) == 0 {
+			continue
+		}
+		if from != nil {
+			return Step{}, false, fmt.Errorf("converters from %s and %s both produce %s", from, source, paramType)
+		}
+		from = source
+		adapter = Step{Name: fmt.Sprintf("convert %s to %s for %s", source, paramType, consumer), Callable: fn.Interface()}
+	}
+	return adapter, from != nil, nil
+}
+
+// plannedSteps returns the steps in execution order with the adapter steps
+// inserted before the steps they feed, and the names of the adapter steps.
+func (p *Pipeline) plannedSteps() ([]Step, map[string]bool) {
+	steps := p.orderedSteps()
+	if p.config.Converters == nil {
+		return steps, nil
+	}
+	state := p.simulateState()
+	p.simulateSteps(state)
+	if len(state.adapters) == 0 {
+		return steps, nil
+	}
+	adapters := make(map[string]bool)
+	planned := make([]Step, 0, len(steps)+len(state.adapters))
+	for _, step := range steps {
+		for _, a := range state.adapters {
+			if a.before == step.Name {
+				planned = append(planned, a.step)
+				adapters[a.step.Name] = true
+			}
+		}
+		planned = append(planned, step)
+	}
+	return planned, adapters
+}
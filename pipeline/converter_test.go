@@ -0,0 +1,66 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRegisterConverterDuringRun(t *testing.T) {
+	p := NewPipeline(nil, discardLogger)
+	if err := p.RegisterConverter(strconv.Itoa); err != nil {
+		t.Fatal(err)
+	}
+	p.AddStep("produce", func() int { return 42 })
+	p.AddStep("consume", func(s string) string { return s + "!" })
+
+	converters := []interface{}{
+		func(int8) string { return "" },
+		func(int16) string { return "" },
+		func(int32) string { return "" },
+		func(int64) string { return "" },
+		func(uint) string { return "" },
+		func(uint8) string { return "" },
+		func(uint16) string { return "" },
+		func(uint32) string { return "" },
+		func(uint64) string { return "" },
+		func(float32) string { return "" },
+		func(float64) string { return "" },
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, fn := range converters {
+			if err := p.RegisterConverter(fn); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := p.Run(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got := res.Steps[len(res.Steps)-1].Outputs; len(got) != 1 || got[0] != "42!" {
+				t.Errorf("consume outputs = %v, want [42!]", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	shared := NewConverterRegistry()
+	q := NewPipeline(&PipelineConfig{Converters: shared}, discardLogger)
+	if err := q.RegisterConverter(strconv.Itoa); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := shared.converter(reflect.TypeOf(0), reflect.TypeOf("")); ok {
+		t.Error("RegisterConverter wrote to the registry set on the config")
+	}
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrBindingCycle is returned by Execute when steps bind to each other's outputs
+// in a cycle, so that none of them can run first.
+var ErrBindingCycle = errors.New("binding cycle")
+
+// cycleHint explains the arrows of the cycle paths in messages.
+const cycleHint = "(each step binds to the outputs of the next)"
+
+// bindingDeps returns, for every step, the steps whose outputs its bindings name,
+// in binding order.
+func (p *Pipeline) bindingDeps() map[string][]string {
+	deps := make(map[string][]string)
+	for _, step := range p.steps {
+		stepCfg, ok := p.config.StepConfigs[step.Name]
+		if !ok {
+			continue
+		}
+		for _, binding := range stepCfg.ArgBindings {
+			if binding == nil {
+				continue
+			}
+			switch binding.Source {
+			case ArgSourceFunctionOutput:
+				deps[step.Name] = append(deps[step.Name], binding.Name)
+			case ArgSourceCollect:
+				deps[step.Name] = append(deps[step.Name], binding.Names...)
+			}
+		}
+	}
+	return deps
+}
+
+// bindingCycles returns every cycle of steps bound to each other's outputs, each
+// as a path starting and ending with the same step, such as [a b a].
+func (p *Pipeline) bindingCycles() [][]string {
+	deps := p.bindingDeps()
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+	var cycles [][]string
+	seen := make(map[string]bool)
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range deps[name] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := slices.Index(stack, dep)
+				cycle := append(slices.Clone(stack[start:]), dep)
+				key := slices.Clone(cycle[:len(cycle)-1])
+				slices.Sort(key)
+				if k := strings.Join(key, "\x00"); !seen[k] {
+					seen[k] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+	}
+	for _, step := range p.orderedSteps() {
+		if state[step.Name] == unvisited {
+			visit(step.Name)
+		}
+	}
+	return cycles
+}
+
+// cycleIssues reports every binding cycle, on the first step of its path.
+func (p *Pipeline) cycleIssues() []ValidationIssue {
+	var issues []ValidationIssue
+	for _, cycle := range p.bindingCycles() {
+		issues = append(issues, ValidationIssue{Step: cycle[0], Param: -1,
+			Message: fmt.Sprintf("%s: %s %s", ErrBindingCycle, strings.Join(cycle, " -> "), cycleHint)})
+	}
+	return issues
+}
+
+// checkCycles returns an error listing every binding cycle, if there is one.
+func (p *Pipeline) checkCycles() error {
+	cycles := p.bindingCycles()
+	if len(cycles) == 0 {
+		return nil
+	}
+	paths := make([]string, len(cycles))
+	for i, cycle := range cycles {
+		paths[i] = strings.Join(cycle, " -> ")
+	}
+	return fmt.Errorf("%w: %s %s", ErrBindingCycle, strings.Join(paths, "; "), cycleHint)
+}
@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrDeadLetterNotFound is returned when resubmitting a dead letter that is not in its store.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is the payload of a triggered run that failed permanently, kept in
+// PipelineConfig.DeadLetters to be inspected, edited and resubmitted.
+type DeadLetter struct {
+	ID          string
+	Pipeline    string        // Name of the triggered pipeline in the PipelineRegistry.
+	Inputs      []interface{} // Initial inputs of the triggered run, wrapped as by TypedInput; may be edited before resubmitting.
+	Source      string        // PipelineConfig.Name of the triggering pipeline.
+	SourceRunID string        // Run that triggered it.
+	ChainID     string        // First run of its chain, see RunInfo.ChainID.
+	Attempts    int           // Runs failed so far, resubmissions included.
+	Err         string        // Error of the last attempt.
+	Time        time.Time     // Of the last attempt.
+	Chain       []string      // Registry names of the pipelines run before it in its chain.
+}
+
+// DeadLetterStore keeps the dead letters of failed triggered runs. Implementations
+// persisting letters must encode their Inputs, whose types only the pipelines know.
+type DeadLetterStore interface {
+	// Put adds the letter, or replaces the one with the same ID. It rejects
+	// letters with an untyped nil input with ErrUntypedNil.
+	Put(letter DeadLetter) error
+	Get(id string) (DeadLetter, bool, error)
+	// List returns every letter, oldest first.
+	List() ([]DeadLetter, error)
+	Delete(id string) error
+}
+
+// MemoryDeadLetterStore keeps dead letters in memory. It is safe for concurrent use.
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{}
+}
+
+// Put adds or replaces the letter; it fails with ErrUntypedNil if an input is an
+// untyped nil, which no run can accept.
+func (s *MemoryDeadLetterStore) Put(letter DeadLetter) error {
+	if err := letter.checkInputs(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letter.Inputs = slices.Clone(letter.Inputs)
+	for i := range s.letters {
+		if s.letters[i].ID == letter.ID {
+			s.letters[i] = letter
+			return nil
+		}
+	}
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *MemoryDeadLetterStore) Get(id string) (DeadLetter, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, letter := range s.letters {
+		if letter.ID == id {
+			letter.Inputs = slices.Clone(letter.Inputs)
+			return letter, true, nil
+		}
+	}
+	return DeadLetter{}, false, nil
+}
+
+func (s *MemoryDeadLetterStore) List() ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters := slices.Clone(s.letters)
+	for i := range letters {
+		letters[i].Inputs = slices.Clone(letters[i].Inputs)
+	}
+	return letters, nil
+}
+
+func (s *MemoryDeadLetterStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = slices.DeleteFunc(s.letters, func(letter DeadLetter) bool { return letter.ID == id })
+	return nil
+}
+
+// Resubmit runs the triggered pipeline of the dead letter id from store with the
+// letter's inputs, as edited with Put if needed, in the chain of the original run.
+// The letter is deleted if the run succeeds, and updated with the new failure otherwise.
+func (r *PipelineRegistry) Resubmit(ctx context.Context, store DeadLetterStore, id string) (*Result, error) {
+	letter, ok, err := store.Get(id)
+	if err != nil {
+		return &Result{RunID: runID(ctx)}, err
+	}
+	if !ok {
+		return &Result{RunID: runID(ctx)}, fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+	if err := letter.checkInputs(); err != nil {
+		return &Result{RunID: runID(ctx)}, fmt.Errorf("resubmit: %w", err)
+	}
+	p, ok := r.Lookup(letter.Pipeline)
+	if !ok {
+		return &Result{RunID: runID(ctx)}, fmt.Errorf("resubmit %s: pipeline %s not registered", id, letter.Pipeline)
+	}
+
+	link := chainLink{root: letter.ChainID, parent: letter.SourceRunID, pipelines: append(slices.Clone(letter.Chain), letter.Pipeline)}
+	result, runErr := p.Run(context.WithValue(ctx, chainKey{}, link), letter.Inputs...)
+	if runErr == nil {
+		return result, store.Delete(id)
+	}
+	letter.Attempts++
+	letter.Err, letter.Time = runErr.Error(), time.Now()
+	if err := store.Put(letter); err != nil {
+		p.logger.Warnf("Could not update dead letter %s: %v", id, err)
+	}
+	return result, runErr
+}
+
+// checkInputs rejects untyped nil inputs, which would fail the run before its first step.
+func (letter DeadLetter) checkInputs() error {
+	for i, input := range letter.Inputs {
+		if input == nil {
+			return fmt.Errorf("dead letter %s: input %d: %w", letter.ID, i, ErrUntypedNil)
+		}
+	}
+	return nil
+}
+
+// deadLetter stores the payload of a triggered run that failed permanently in
+// PipelineConfig.DeadLetters, if set.
+func (p *Pipeline) deadLetter(trigger Trigger, link chainLink, inputs []interface{}, attempts int, err error) {
+	if p.config.DeadLetters == nil {
+		return
+	}
+	letter := DeadLetter{
+		ID:          NewRunID(),
+		Pipeline:    trigger.Pipeline,
+		Inputs:      inputs,
+		Source:      p.config.Name,
+		SourceRunID: link.parent,
+		ChainID:     link.root,
+		Attempts:    attempts,
+		Err:         err.Error(),
+		Time:        time.Now(),
+		Chain:       link.pipelines,
+	}
+	if putErr := p.config.DeadLetters.Put(letter); putErr != nil {
+		p.logger.Errorf("Could not store dead letter of pipeline %q: %v", trigger.Pipeline, putErr)
+		return
+	}
+	p.logger.Warnf("Triggered run of pipeline %q stored as dead letter %s", trigger.Pipeline, letter.ID)
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestDeadLetterRejectsUntypedNil(t *testing.T) {
+	store := NewMemoryDeadLetterStore()
+	err := store.Put(DeadLetter{ID: "x", Pipeline: "next", Inputs: []interface{}{1, nil}})
+	if !errors.Is(err, ErrUntypedNil) {
+		t.Fatalf("Put = %v, want ErrUntypedNil", err)
+	}
+}
+
+func TestResubmitReplaysTypedInputs(t *testing.T) {
+	registry := NewPipelineRegistry()
+	store := NewMemoryDeadLetterStore()
+	cfg := NewPipelineConfig()
+	cfg.Pipelines = registry
+	cfg.DeadLetters = store
+	cfg.Triggers = []Trigger{{Pipeline: "next", Steps: []string{"open"}}}
+	first := NewPipeline(cfg, nil)
+	first.AddStep("open", func() (io.Reader, int, error) { return nil, 42, nil })
+
+	errBoom := errors.New("boom")
+	failing := true
+	var gotReader io.Reader
+	gotCount := 0
+	next := NewPipeline(nil, nil)
+	next.AddStep("read", func(r io.Reader, n int) error {
+		if failing {
+			return errBoom
+		}
+		gotReader, gotCount = r, n
+		return nil
+	})
+	if err := registry.Register("first", first); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("next", next); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := first.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	letters, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("store has %d dead letters, want 1", len(letters))
+	}
+
+	failing = false
+	if _, err := registry.Resubmit(context.Background(), store, letters[0].ID); err != nil {
+		t.Fatalf("Resubmit: %v", err)
+	}
+	if gotReader != nil || gotCount != 42 {
+		t.Errorf("read got (%v, %d), want (nil, 42)", gotReader, gotCount)
+	}
+	if _, ok, _ := store.Get(letters[0].ID); ok {
+		t.Error("the dead letter is still stored after a successful resubmission")
+	}
+}
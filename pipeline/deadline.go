@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DeadlineExceededError is returned when a run does not finish within
+// PipelineConfig.Deadline or MaxDuration.
+type DeadlineExceededError struct {
+	Deadline  time.Time
+	Completed []string // Main steps completed before the deadline, in order.
+	Pending   []string // Main steps not completed, including the one interrupted, if any.
+	Err       error    // Error the run stopped with.
+}
+
+func (e *DeadlineExceededError) Error() string {
+	msg := fmt.Sprintf("run deadline %s exceeded after %d step(s)", e.Deadline.Format(time.RFC3339), len(e.Completed))
+	if len(e.Pending) > 0 {
+		msg += "; pending: " + strings.Join(e.Pending, ", ")
+	}
+	return msg
+}
+
+func (e *DeadlineExceededError) Unwrap() error {
+	return e.Err
+}
+
+// runDeadline returns the deadline set by PipelineConfig.Deadline and MaxDuration
+// for a run starting now, whichever is earlier.
+func (p *Pipeline) runDeadline() (time.Time, bool) {
+	deadline := p.config.Deadline
+	if p.config.MaxDuration > 0 {
+		if d := time.Now().Add(p.config.MaxDuration); deadline.IsZero() || d.Before(deadline) {
+			deadline = d
+		}
+	}
+	return deadline, !deadline.IsZero()
+}
+
+// deadlineError wraps err in a *DeadlineExceededError if the run stopped because
+// its own deadline passed, rather than because of its caller's context.
+func (p *Pipeline) deadlineError(ctx, runCtx context.Context, result *Result, err error) error {
+	deadline, ok := runCtx.Deadline()
+	if err == nil || ctx.Err() != nil || runCtx.Err() != context.DeadlineExceeded || !ok {
+		return err
+	}
+	completed := make(map[string]bool)
+	e := &DeadlineExceededError{Deadline: deadline, Err: err}
+	for _, record := range result.Steps {
+		if !record.Teardown && !record.Assertion && record.Err == nil {
+			completed[record.Name] = true
+			e.Completed = append(e.Completed, record.Name)
+		}
+	}
+	steps, _ := p.plannedSteps()
+	for _, step := range steps {
+		if !completed[step.Name] {
+			e.Pending = append(e.Pending, step.Name)
+		}
+	}
+	return e
+}
@@ -0,0 +1,423 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"reflect"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Registry maps function names used in pipeline definitions to Go callables.
+type Registry struct {
+	mu    sync.RWMutex
+	funcs map[string]interface{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{funcs: make(map[string]interface{})}
+}
+
+// Register makes fn available to definitions under name.
+func (r *Registry) Register(name string, fn interface{}) error {
+	if t := reflect.TypeOf(fn); t == nil || t.Kind() != reflect.Func {
+		return fmt.Errorf("registry: %q is not a function", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.funcs[name]; exists {
+		return fmt.Errorf("registry: function %q already registered", name)
+	}
+	r.funcs[name] = fn
+	return nil
+}
+
+// Lookup returns the function registered under name.
+func (r *Registry) Lookup(name string) (interface{}, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn, ok := r.funcs[name]
+	return fn, ok
+}
+
+// Definition is the declarative (YAML or JSON) form of a pipeline.
+type Definition struct {
+	Name             string           `json:"name,omitempty" yaml:"name,omitempty"`
+	MissingArgPolicy string           `json:"missing_arg_policy,omitempty" yaml:"missing_arg_policy,omitempty"`
+	StepOrder        []string         `json:"step_order,omitempty" yaml:"step_order,omitempty"`
+	OutputFilter     []string         `json:"output_filter,omitempty" yaml:"output_filter,omitempty"`
+	Steps            []StepDefinition `json:"steps" yaml:"steps"`
+}
+
+type StepDefinition struct {
+	Name    string              `json:"name" yaml:"name"`
+	Func    string              `json:"func,omitempty" yaml:"func,omitempty"` // Registry name, defaults to Name.
+	Outputs []string            `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Args    []BindingDefinition `json:"args,omitempty" yaml:"args,omitempty"`
+
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// BindingDefinition describes one parameter binding. When Source is empty it is
+// inferred: "step" means a function output, "steps" a collection, "name" an
+// environment variable, "value" a literal, and "index" alone an initial input.
+type BindingDefinition struct {
+	Source string      `json:"source,omitempty" yaml:"source,omitempty"` // default, initial, input, output, literal, env or collect.
+	Step   string      `json:"step,omitempty" yaml:"step,omitempty"`
+	Steps  []string    `json:"steps,omitempty" yaml:"steps,omitempty"` // Steps to collect from.
+	Output string      `json:"output,omitempty" yaml:"output,omitempty"`
+	Index  *int        `json:"index,omitempty" yaml:"index,omitempty"`
+	Name   string      `json:"name,omitempty" yaml:"name,omitempty"`
+	Value  interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Default is used when the binding cannot be resolved.
+	Default interface{} `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// ParseDefinition decodes a YAML or JSON pipeline definition.
+func ParseDefinition(data []byte) (*Definition, error) {
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("parse pipeline definition: %w", err)
+	}
+	return &def, nil
+}
+
+// LoadPipeline parses a YAML or JSON definition and builds a Pipeline from it.
+func LoadPipeline(data []byte, registry *Registry, logger Logger) (*Pipeline, error) {
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, err
+	}
+	return def.Build(registry, logger)
+}
+
+// LoadPipelineFile is LoadPipeline reading the definition from path.
+func LoadPipelineFile(path string, registry *Registry, logger Logger) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pipeline definition: %w", err)
+	}
+	return LoadPipeline(data, registry, logger)
+}
+
+// ValidateDefinition checks a candidate YAML or JSON definition against registry
+// without running it or keeping the pipeline built from it, and returns every error
+// and warning found. inputs stand for the initial inputs the pipeline will be run
+// with; only their types matter. An error is returned only if data cannot be parsed.
+func ValidateDefinition(data []byte, registry *Registry, inputs ...interface{}) ([]ValidationIssue, error) {
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, err
+	}
+	return def.Check(registry, inputs...), nil
+}
+
+// Check returns the problems of the definition: steps that cannot be built from
+// registry and, if every step can, the issues Validate would report once inputs
+// are added to the built pipeline.
+func (d *Definition) Check(registry *Registry, inputs ...interface{}) []ValidationIssue {
+	var issues []ValidationIssue
+	if _, err := d.missingArgPolicy(); err != nil {
+		issues = append(issues, ValidationIssue{Param: -1, Message: err.Error()})
+	}
+	seen := make(map[string]bool)
+	for _, sd := range d.Steps {
+		if seen[sd.Name] {
+			issues = append(issues, ValidationIssue{Step: sd.Name, Param: -1, Message: "step name used more than once"})
+		}
+		seen[sd.Name] = true
+		if _, _, err := sd.build(registry); err != nil {
+			var argErr *argDefinitionError
+			if errors.As(err, &argErr) {
+				// Such as a literal that does not fit its parameter.
+				issues = append(issues, ValidationIssue{Step: argErr.step, Param: argErr.param, Message: argErr.err.Error()})
+			} else {
+				issues = append(issues, ValidationIssue{Param: -1, Message: err.Error()})
+			}
+		}
+	}
+	for _, entry := range d.OutputFilter {
+		filter, errs := compileOutputFilter([]string{entry})
+		if len(errs) > 0 {
+			continue // reported by simulate
+		}
+		matched := false
+		for name := range seen {
+			matched = matched || filter.match(name)
+		}
+		if !matched {
+			issues = append(issues, ValidationIssue{Param: -1, Warning: true,
+				Message: fmt.Sprintf("output filter %q matches no step", entry)})
+		}
+	}
+	if len(issues) > 0 && !onlyWarnings(issues) {
+		return issues
+	}
+
+	p, err := d.Build(registry, discardLogger)
+	if err != nil {
+		return append(issues, ValidationIssue{Param: -1, Message: err.Error()})
+	}
+	p.AddInitialInputs(inputs...)
+	simulated, _ := p.simulate()
+	return append(issues, simulated...)
+}
+
+func onlyWarnings(issues []ValidationIssue) bool {
+	for _, issue := range issues {
+		if !issue.Warning {
+			return false
+		}
+	}
+	return true
+}
+
+var discardLogger Logger = NewSlogLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+// Build creates a Pipeline whose steps call the functions registered under the definition's names.
+func (d *Definition) Build(registry *Registry, logger Logger) (*Pipeline, error) {
+	config := NewPipelineConfig()
+	config.Name = d.Name
+	config.StepOrder = d.StepOrder
+	config.OutputFilter = d.OutputFilter
+	policy, err := d.missingArgPolicy()
+	if err != nil {
+		return nil, err
+	}
+	config.MissingArgPolicy = policy
+
+	p := NewPipeline(config, logger)
+	for _, sd := range d.Steps {
+		if _, exists := config.StepConfigs[sd.Name]; exists {
+			return nil, fmt.Errorf("definition: step %s: name used more than once", sd.Name)
+		}
+		fn, stepCfg, err := sd.build(registry)
+		if err != nil {
+			return nil, err
+		}
+		config.StepConfigs[sd.Name] = stepCfg
+		p.AddStep(sd.Name, fn)
+	}
+	return p, nil
+}
+
+func (d *Definition) missingArgPolicy() (MissingArgPolicy, error) {
+	switch d.MissingArgPolicy {
+	case "", "use_latest":
+		return MissingArgPolicyUseLatest, nil
+	case "fail":
+		return MissingArgPolicyFail, nil
+	case "zero_value":
+		return MissingArgPolicyZeroValue, nil
+	default:
+		return 0, fmt.Errorf("definition: unknown missing_arg_policy %q", d.MissingArgPolicy)
+	}
+}
+
+// build looks up the step's function in registry and derives its StepConfig.
+func (sd StepDefinition) build(registry *Registry) (interface{}, *StepConfig, error) {
+	if sd.Name == "" {
+		return nil, nil, fmt.Errorf("definition: step without a name")
+	}
+	funcName := sd.Func
+	if funcName == "" {
+		funcName = sd.Name
+	}
+	fn, ok := registry.Lookup(funcName)
+	if !ok {
+		return nil, nil, fmt.Errorf("definition: step %s: function %q is not registered", sd.Name, funcName)
+	}
+	stepCfg, err := sd.stepConfig(reflect.TypeOf(fn))
+	if err != nil {
+		return nil, nil, err
+	}
+	return fn, stepCfg, nil
+}
+
+func (sd StepDefinition) stepConfig(fnType reflect.Type) (*StepConfig, error) {
+	if len(sd.Args) > fnType.NumIn() {
+		return nil, fmt.Errorf("definition: step %s: %d args declared but function takes %d",
+			sd.Name, len(sd.Args), fnType.NumIn())
+	}
+	if len(sd.Outputs) > fnType.NumOut() {
+		return nil, fmt.Errorf("definition: step %s: %d outputs named but function returns %d",
+			sd.Name, len(sd.Outputs), fnType.NumOut())
+	}
+	cfg := &StepConfig{OutputNames: sd.Outputs, Description: sd.Description, Tags: sd.Tags, Disabled: sd.Disabled}
+	for i, bd := range sd.Args {
+		binding, err := bd.binding(fnType.In(i))
+		if err != nil {
+			return nil, &argDefinitionError{step: sd.Name, param: i, err: err}
+		}
+		cfg.ArgBindings = append(cfg.ArgBindings, binding)
+	}
+	return cfg, nil
+}
+
+// argDefinitionError is returned for a binding definition that cannot apply to its parameter.
+type argDefinitionError struct {
+	step  string
+	param int
+	err   error
+}
+
+func (e *argDefinitionError) Error() string {
+	return fmt.Sprintf("definition: step %s: arg %d: %v", e.step, e.param, e.err)
+}
+
+func (e *argDefinitionError) Unwrap() error {
+	return e.err
+}
+
+func (bd BindingDefinition) binding(paramType reflect.Type) (*ArgBinding, error) {
+	binding, err := bd.sourceBinding(paramType)
+	if err != nil || bd.Default == nil {
+		return binding, err
+	}
+	if binding.Default, err = coerceLiteral(bd.Default, paramType); err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+	return binding, nil
+}
+
+func (bd BindingDefinition) sourceBinding(paramType reflect.Type) (*ArgBinding, error) {
+	index := 0
+	if bd.Index != nil {
+		index = *bd.Index
+	}
+
+	source := bd.Source
+	if source == "" {
+		switch {
+		case bd.Step != "":
+			source = "output"
+		case len(bd.Steps) > 0:
+			source = "collect"
+		case bd.Name != "":
+			source = "env"
+		case bd.Value != nil:
+			source = "literal"
+		case bd.Index != nil:
+			source = "initial"
+		default:
+			source = "default"
+		}
+	}
+
+	switch source {
+	case "default":
+		return &ArgBinding{Source: ArgSourceDefault}, nil
+	case "initial":
+		return &ArgBinding{Source: ArgSourceInitial, Index: index}, nil
+	case "input":
+		if bd.Name == "" {
+			return nil, fmt.Errorf("input binding without an input name")
+		}
+		return &ArgBinding{Source: ArgSourceNamedInput, Name: bd.Name}, nil
+	case "output":
+		if bd.Step == "" {
+			return nil, fmt.Errorf("output binding without a step")
+		}
+		return &ArgBinding{Source: ArgSourceFunctionOutput, Name: bd.Step, Index: index, OutputName: bd.Output}, nil
+	case "literal":
+		value, err := coerceLiteral(bd.Value, paramType)
+		if err != nil {
+			return nil, err
+		}
+		return &ArgBinding{Source: ArgSourceLiteral, Value: value}, nil
+	case "env":
+		if bd.Name == "" {
+			return nil, fmt.Errorf("env binding without a variable name")
+		}
+		binding := &ArgBinding{Source: ArgSourceEnv, Name: bd.Name}
+		if bd.Value != nil {
+			binding.Value = fmt.Sprint(bd.Value)
+		}
+		return binding, nil
+	case "collect":
+		return &ArgBinding{Source: ArgSourceCollect, Names: bd.Steps}, nil
+	default:
+		return nil, fmt.Errorf("unknown binding source %q", source)
+	}
+}
+
+// coerceLiteral converts a decoded YAML/JSON scalar to the parameter type, so that
+// e.g. a literal 10 can feed an int64 parameter and "5s" a time.Duration.
+func coerceLiteral(value interface{}, paramType reflect.Type) (interface{}, error) {
+	if value == nil {
+		return nil, fmt.Errorf("literal binding without a value")
+	}
+	val := reflect.ValueOf(value)
+	if val.Type().AssignableTo(paramType) {
+		return value, nil
+	}
+	if s, isString := value.(string); isString && envTypeSupported(paramType) {
+		parsed, err := parseEnvValue(s, paramType)
+		if err != nil {
+			return nil, err
+		}
+		return parsed.Interface(), nil
+	}
+	if isNumberKind(val.Kind()) && isNumberKind(paramType.Kind()) {
+		if err := checkNumberFits(val, paramType); err != nil {
+			return nil, err
+		}
+		return val.Convert(paramType).Interface(), nil
+	}
+	return nil, fmt.Errorf("literal of type %s cannot be used as %s", val.Type(), paramType)
+}
+
+// checkNumberFits fails if converting the number val to paramType would change its value.
+func checkNumberFits(val reflect.Value, paramType reflect.Type) error {
+	target := reflect.New(paramType).Elem()
+	fits := true
+	switch {
+	case val.CanInt():
+		n := val.Int()
+		switch {
+		case target.CanInt():
+			fits = !target.OverflowInt(n)
+		case target.CanUint():
+			fits = n >= 0 && !target.OverflowUint(uint64(n))
+		}
+	case val.CanUint():
+		n := val.Uint()
+		switch {
+		case target.CanInt():
+			fits = n <= math.MaxInt64 && !target.OverflowInt(int64(n))
+		case target.CanUint():
+			fits = !target.OverflowUint(n)
+		}
+	default:
+		f := val.Float()
+		switch {
+		case target.CanInt(), target.CanUint():
+			if f != math.Trunc(f) {
+				return fmt.Errorf("literal %v is not an integer and cannot be used as %s", f, paramType)
+			}
+			if target.CanInt() {
+				fits = f >= math.MinInt64 && f < math.MaxInt64 && !target.OverflowInt(int64(f))
+			} else {
+				fits = f >= 0 && f < math.MaxUint64 && !target.OverflowUint(uint64(f))
+			}
+		default:
+			fits = !target.OverflowFloat(f)
+		}
+	}
+	if !fits {
+		return fmt.Errorf("literal %v overflows %s", val, paramType)
+	}
+	return nil
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return (k >= reflect.Int && k <= reflect.Uint64) || k == reflect.Float32 || k == reflect.Float64
+}
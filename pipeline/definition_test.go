@@ -0,0 +1,22 @@
+package pipeline
+
+import "testing"
+
+func TestDefinitionRejectsDuplicateStepNames(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register("extract", func() int { return 1 }); err != nil {
+		t.Fatal(err)
+	}
+	def := &Definition{Steps: []StepDefinition{
+		{Name: "extract"},
+		{Name: "extract", Outputs: []string{"n"}},
+	}}
+
+	if _, err := def.Build(registry, discardLogger); err == nil {
+		t.Error("Build succeeded, want an error for the duplicate step name")
+	}
+	issues := def.Check(registry)
+	if len(issues) != 1 || issues[0].Warning || issues[0].Step != "extract" {
+		t.Errorf("Check = %v, want one error for step extract", issues)
+	}
+}
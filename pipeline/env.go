@@ -0,0 +1,91 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (p *Pipeline) resolveArgFromEnv(step Step, paramType reflect.Type, varName string, def interface{}) (reflect.Value, error) {
+	raw, set := os.LookupEnv(varName)
+	if !set {
+		if def == nil {
+			return reflect.Value{}, withCode(CodeArgNotFound, fmt.Errorf("environment variable %s is not set and has no default",
+				varName))
+		}
+		s, isString := def.(string)
+		if !isString {
+			// A typed default is used as-is, like a literal.
+			return p.resolveArgFromLiteral(step, paramType, def)
+		}
+		raw = s
+	}
+	val, err := parseEnvValue(raw, paramType)
+	if err != nil {
+		return reflect.Value{}, withCode(CodeTypeMismatch, fmt.Errorf("environment variable %s: %w", varName, err))
+	}
+	return val, nil
+}
+
+// parseEnvValue converts the raw string of an environment variable into a value of type t.
+func parseEnvValue(raw string, t reflect.Type) (reflect.Value, error) {
+	if t == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot parse %q as %s: %w", raw, t, err)
+		}
+		return reflect.ValueOf(d), nil
+	}
+
+	val := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		val.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot parse %q as %s: %w", raw, t, err)
+		}
+		val.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot parse %q as %s: %w", raw, t, err)
+		}
+		val.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot parse %q as %s: %w", raw, t, err)
+		}
+		val.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("cannot parse %q as %s: %w", raw, t, err)
+		}
+		val.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported parameter type %s", t)
+	}
+	return val, nil
+}
+
+// envTypeSupported reports whether parseEnvValue can produce a value of type t.
+func envTypeSupported(t reflect.Type) bool {
+	if t == durationType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is a stable, machine-readable category of a pipeline failure, for
+// integrations that branch on the kind of failure rather than on its message.
+type ErrorCode string
+
+const (
+	CodeArgNotFound      ErrorCode = "ARG_NOT_FOUND"     // no value could be resolved for a parameter
+	CodeTypeMismatch     ErrorCode = "TYPE_MISMATCH"     // a resolved value does not fit the parameter type
+	CodeStepTimeout      ErrorCode = "STEP_TIMEOUT"      // a deadline was exceeded
+	CodeCancelled        ErrorCode = "CANCELLED"         // the run's context was cancelled
+	CodeCircuitOpen      ErrorCode = "CIRCUIT_OPEN"      // the step's circuit breaker rejected the call
+	CodeConcurrencyBusy  ErrorCode = "CONCURRENCY_BUSY"  // the concurrency group was held by another run
+	CodeInvalidInput     ErrorCode = "INVALID_INPUT"     // an initial input was rejected
+	CodeValidationFailed ErrorCode = "VALIDATION_FAILED" // Validate found errors
+	CodePreflightFailed  ErrorCode = "PREFLIGHT_FAILED"  // a preflight check failed
+	CodeAssertionFailed  ErrorCode = "ASSERTION_FAILED"  // an assert step failed
+	CodeStepFailed       ErrorCode = "STEP_FAILED"       // the step itself returned an error
+	CodeUnknown          ErrorCode = "UNKNOWN"
+)
+
+// codedError attaches an ErrorCode to an error without changing its message.
+type codedError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+func withCode(code ErrorCode, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// ErrorCodeOf returns the code of the first categorized failure in err's chain,
+// CodeStepFailed for other step failures and CodeUnknown otherwise. It returns ""
+// for a nil error.
+func ErrorCodeOf(err error) ErrorCode {
+	var coded *codedError
+	var stepErr *StepError
+	var validationErr *ValidationError
+	var preflightErr *PreflightError
+	var assertionErr *AssertionError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrWaitTimeout):
+		return CodeStepTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	case errors.Is(err, ErrCircuitOpen):
+		return CodeCircuitOpen
+	case errors.Is(err, ErrConcurrencyGroupBusy):
+		return CodeConcurrencyBusy
+	case errors.Is(err, ErrUntypedNil):
+		return CodeInvalidInput
+	case errors.Is(err, ErrBindingCycle), errors.Is(err, ErrChainCycle):
+		return CodeValidationFailed
+	case errors.As(err, &validationErr):
+		return CodeValidationFailed
+	case errors.As(err, &preflightErr):
+		return CodePreflightFailed
+	case errors.As(err, &assertionErr):
+		return CodeAssertionFailed
+	case errors.As(err, &stepErr):
+		return CodeStepFailed
+	default:
+		return CodeUnknown
+	}
+}
+
+// MultiError aggregates the failures of a run executed with ErrorPolicyContinueCollect.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d step(s) failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap allows errors.Is and errors.As to inspect every failure.
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// StepError reports the failure of a single step. Step failures returned by
+// Execute and Run, alone or inside a MultiError, are *StepError values, so
+// errors.As can recover which step failed and errors.Is reaches the Cause.
+type StepError struct {
+	Pipeline string // PipelineConfig.Name, possibly empty
+	Step     string
+	Index    int       // position of the step's record in Result.Steps
+	Code     ErrorCode // category of Cause, see ErrorCodeOf
+	Cause    error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %s: %v", e.Step, e.Cause)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Cause
+}
+
+// MarshalJSON encodes the failure with its code, for JSON outputs and HTTP responses.
+func (e *StepError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Pipeline string    `json:"pipeline,omitempty"`
+		Step     string    `json:"step"`
+		Index    int       `json:"index"`
+		Code     ErrorCode `json:"code"`
+		Message  string    `json:"message"`
+	}{e.Pipeline, e.Step, e.Index, e.Code, e.Cause.Error()})
+}
@@ -0,0 +1,151 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Explain describes, without calling any step, how the pipeline would execute:
+// the effective step order, each step's parameters with the source they would be
+// resolved from (binding, initial input, prior step output), and the parameters
+// that cannot be resolved. Like Validate, it works on types only.
+func (p *Pipeline) Explain() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	issues, resolutions := p.simulate()
+	byStep := make(map[string][]paramResolution)
+	for _, res := range resolutions {
+		byStep[res.Step] = append(byStep[res.Step], res)
+	}
+
+	var b strings.Builder
+	inputs := p.context.InitialValues()
+	fmt.Fprintf(&b, "Initial inputs (%d):\n", len(inputs))
+	names := make(map[int]string)
+	for name, i := range p.context.inputNames {
+		names[i] = name
+	}
+	for i, v := range inputs {
+		if name, ok := names[i]; ok {
+			fmt.Fprintf(&b, "  %d. %s %q\n", i, v.Type(), name)
+		} else {
+			fmt.Fprintf(&b, "  %d. %s\n", i, v.Type())
+		}
+	}
+
+	steps, adapters := p.plannedSteps()
+	fmt.Fprintf(&b, "Steps (%d):\n", len(steps))
+	for i, step := range steps {
+		fmt.Fprintf(&b, "  %d. %s", i+1, step.Name)
+		if stepCfg, ok := p.config.StepConfigs[step.Name]; ok && stepCfg != nil && len(stepCfg.Tags) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(stepCfg.Tags, ", "))
+		}
+		switch {
+		case p.deselected(step.Name):
+			b.WriteString(" (not selected by tags)\n")
+			continue
+		case p.isDisabled(step.Name):
+			b.WriteString(" (disabled)\n")
+			continue
+		case isRunner(step):
+			b.WriteString(" (StepRunner: parameters resolved when it runs)\n")
+			continue
+		}
+		if p.isLazy(step) {
+			b.WriteString(" (lazy: runs when an argument is bound to its outputs)")
+		}
+		if adapters[step.Name] {
+			b.WriteString(" (adapter)")
+		}
+		if t := reflect.TypeOf(step.Callable); t != nil && t.Kind() == reflect.Func {
+			fmt.Fprintf(&b, " %s", t)
+		}
+		b.WriteByte('\n')
+		if stepCfg, ok := p.config.StepConfigs[step.Name]; ok && stepCfg != nil && stepCfg.Description != "" {
+			fmt.Fprintf(&b, "       %s\n", stepCfg.Description)
+		}
+		for _, res := range byStep[step.Name] {
+			fmt.Fprintf(&b, "       param %d %s <- %s\n", res.Param, res.Type, p.explainSource(res))
+		}
+	}
+	p.explainSteps(&b, "Assertions", p.assertSteps)
+	p.explainSteps(&b, "Teardown", p.teardownSteps)
+
+	if len(issues) > 0 {
+		fmt.Fprintf(&b, "Issues (%d):\n", len(issues))
+		for _, issue := range issues {
+			kind := "error"
+			if issue.Warning {
+				kind = "warning"
+			}
+			fmt.Fprintf(&b, "  %s: %s\n", kind, issue)
+		}
+	}
+	return b.String()
+}
+
+func (p *Pipeline) explainSteps(b *strings.Builder, title string, steps []Step) {
+	if len(steps) == 0 {
+		return
+	}
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Name
+	}
+	fmt.Fprintf(b, "%s (%d): %s\n", title, len(steps), strings.Join(names, ", "))
+}
+
+// explainSource describes where the value of a parameter would come from.
+func (p *Pipeline) explainSource(res paramResolution) string {
+	binding := res.Binding
+	switch {
+	case res.Issue != "" && res.From == nil && res.Collected == nil:
+		return "UNRESOLVED: " + res.Issue
+	case res.Injected:
+		return "injected by the engine"
+	case res.Defaulted:
+		return fmt.Sprintf("binding default %v", binding.Default)
+	}
+
+	var src string
+	switch binding.Source {
+	case ArgSourceLiteral:
+		return fmt.Sprintf("literal %v", binding.Value)
+	case ArgSourceEnv:
+		if binding.Value != nil {
+			return fmt.Sprintf("environment variable %s (default %v)", binding.Name, binding.Value)
+		}
+		return "environment variable " + binding.Name
+	case ArgSourceNamedInput:
+		src = fmt.Sprintf("binding to named input %q: ", binding.Name)
+	case ArgSourceInitial, ArgSourceFunctionOutput:
+		src = "binding: "
+	case ArgSourceCollect:
+		src = "binding collecting "
+	}
+
+	switch {
+	case res.From != nil:
+		src += res.From.String()
+		if res.From.Step != "" {
+			if names := p.outputNames(res.From.Step); res.From.Index < len(names) {
+				src += fmt.Sprintf(" (%s)", names[res.From.Index])
+			}
+		}
+	case res.Collected != nil:
+		refs := make([]string, len(res.Collected))
+		for i, ref := range res.Collected {
+			refs[i] = ref.String()
+		}
+		src += "[" + strings.Join(refs, ", ") + "]"
+	case res.Type.Kind() == reflect.Slice && (binding.Source == ArgSourceCollect || binding.Source == ArgSourceDefault):
+		src += "[] (no values of the element type)"
+	default:
+		src += "zero value (no value available)"
+	}
+	if res.Issue != "" {
+		src += " (" + res.Issue + ")"
+	}
+	return src
+}
@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// AddFanOutStep adds a step that applies itemFn to every element of a []T argument
+// concurrently, using at most workers goroutines, and stores the results as []R.
+// itemFn must have the shape func(T) R or func(T) (R, error); the step itself then
+// behaves like func([]T) []R or func([]T) ([]R, error), so its input can be bound
+// like any other parameter. The first item error (by index) fails the step.
+// Goroutines beyond the step's own take a worker from PipelineConfig.Workers.
+func (p *Pipeline) AddFanOutStep(name string, itemFn interface{}, workers int) error {
+	callable, err := fanOutCallable(itemFn, workers, nil)
+	if err != nil {
+		return fmt.Errorf("fan-out step %s: %w", name, err)
+	}
+	p.AddStep(name, callable)
+	return nil
+}
+
+// AddAdaptiveFanOutStep is like AddFanOutStep, but the number of items processed
+// concurrently is governed by limiter, which backs off when item calls fail or slow
+// down and grows again while they succeed. At most limiter.MaxLimit goroutines are used.
+func (p *Pipeline) AddAdaptiveFanOutStep(name string, itemFn interface{}, limiter *AdaptiveLimiter) error {
+	if limiter == nil {
+		return fmt.Errorf("fan-out step %s: limiter is nil", name)
+	}
+	callable, err := fanOutCallable(itemFn, limiter.MaxLimit, limiter)
+	if err != nil {
+		return fmt.Errorf("fan-out step %s: %w", name, err)
+	}
+	p.AddStep(name, callable)
+	return nil
+}
+
+func fanOutCallable(itemFn interface{}, workers int, limiter *AdaptiveLimiter) (interface{}, error) {
+	fnValue := reflect.ValueOf(itemFn)
+	if !fnValue.IsValid() {
+		return nil, fmt.Errorf("item function is nil")
+	}
+	fnType := fnValue.Type()
+	if fnType.Kind() != reflect.Func || fnType.NumIn() != 1 {
+		return nil, fmt.Errorf("item function must take exactly one parameter, got %s", fnType)
+	}
+	returnsErr := fnType.NumOut() == 2 && fnType.Out(1) == errorType
+	if fnType.NumOut() != 1 && !returnsErr {
+		return nil, fmt.Errorf("item function must return R or (R, error), got %s", fnType)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	outSlice := reflect.SliceOf(fnType.Out(0))
+	outs := []reflect.Type{outSlice}
+	if returnsErr {
+		outs = append(outs, errorType)
+	}
+	// The worker pool of the run is injected as a second parameter.
+	stepType := reflect.FuncOf([]reflect.Type{reflect.SliceOf(fnType.In(0)), workerPoolType}, outs, false)
+
+	step := reflect.MakeFunc(stepType, func(args []reflect.Value) []reflect.Value {
+		items := args[0]
+		pool := args[1].Interface().(*workerPool)
+		n := items.Len()
+		results := reflect.MakeSlice(outSlice, n, n)
+		errs := make([]error, n)
+
+		indexes := make(chan int, n)
+		for i := 0; i < n; i++ {
+			indexes <- i
+		}
+		close(indexes)
+		work := func() {
+			in := make([]reflect.Value, 1)
+			for i := range indexes {
+				var start time.Time
+				if limiter != nil {
+					limiter.acquire()
+					start = time.Now()
+				}
+				in[0] = items.Index(i)
+				out := fnValue.Call(in)
+				results.Index(i).Set(out[0])
+				if returnsErr && !out[1].IsNil() {
+					errs[i] = out[1].Interface().(error)
+				}
+				if limiter != nil {
+					limiter.release(time.Since(start), errs[i] != nil)
+				}
+			}
+		}
+
+		// The step's goroutine works too, so the items get done even when no
+		// worker is free; helpers still waiting for one then give up.
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		for w := 1; w < workers && w < n; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if !pool.acquire(done) {
+					return
+				}
+				defer pool.release()
+				work()
+			}()
+		}
+		work()
+		close(done)
+		wg.Wait()
+
+		if !returnsErr {
+			return []reflect.Value{results}
+		}
+		errValue := reflect.Zero(errorType)
+		for i, err := range errs {
+			if err != nil {
+				errValue = reflect.ValueOf(fmt.Errorf("item %d: %w", i, err))
+				break
+			}
+		}
+		return []reflect.Value{results, errValue}
+	})
+	return step.Interface(), nil
+}
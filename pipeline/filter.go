@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexFilterPrefix marks an OutputFilter entry as a regular expression.
+const regexFilterPrefix = "re:"
+
+// outputFilter matches step names against the entries of PipelineConfig.OutputFilter.
+type outputFilter struct {
+	names    map[string]bool
+	globs    []string
+	patterns []*regexp.Regexp
+}
+
+// compileOutputFilter parses the entries of an OutputFilter: exact step names,
+// glob patterns such as "extract-*" (see path.Match) and regular expressions
+// prefixed with "re:", such as "re:^extract-[0-9]+$". Every entry first matches
+// the step with exactly its name, so a step named "load[1]" or "re:x" can always
+// be selected by name. Entries that are invalid patterns are returned as errors
+// and only match by name.
+func compileOutputFilter(entries []string) (*outputFilter, []error) {
+	f := &outputFilter{names: make(map[string]bool)}
+	var errs []error
+	for _, entry := range entries {
+		f.names[entry] = true
+		switch {
+		case strings.HasPrefix(entry, regexFilterPrefix):
+			re, err := regexp.Compile(strings.TrimPrefix(entry, regexFilterPrefix))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("output filter %q: %w", entry, err))
+				continue
+			}
+			f.patterns = append(f.patterns, re)
+		case strings.ContainsAny(entry, `*?[\`):
+			if _, err := path.Match(entry, ""); err != nil {
+				errs = append(errs, fmt.Errorf("output filter %q: %w", entry, err))
+				continue
+			}
+			f.globs = append(f.globs, entry)
+		}
+	}
+	return f, errs
+}
+
+func (f *outputFilter) match(stepName string) bool {
+	if f.names[stepName] {
+		return true
+	}
+	for _, glob := range f.globs {
+		if ok, _ := path.Match(glob, stepName); ok {
+			return true
+		}
+	}
+	for _, re := range f.patterns {
+		if re.MatchString(stepName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOutputFilterMatch(t *testing.T) {
+	tests := []struct {
+		entry string
+		step  string
+		want  bool
+	}{
+		{entry: "load", step: "load", want: true},
+		{entry: "load", step: "loader", want: false},
+		{entry: "extract-*", step: "extract-1", want: true},
+		{entry: "extract-*", step: "extract/1", want: false},
+		{entry: "load[1]", step: "load[1]", want: true},
+		{entry: "load[1]", step: "load1", want: true},
+		{entry: "load[", step: "load[", want: true},
+		{entry: "what?", step: "what?", want: true},
+		{entry: `a\b`, step: `a\b`, want: true},
+		{entry: "re:x", step: "re:x", want: true},
+		{entry: "re:^extract-[0-9]+$", step: "extract-12", want: true},
+		{entry: "re:^extract/", step: "extract/1", want: true},
+	}
+	for _, tt := range tests {
+		f, _ := compileOutputFilter([]string{tt.entry})
+		if got := f.match(tt.step); got != tt.want {
+			t.Errorf("filter %q matching %q = %v, want %v", tt.entry, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestOutputFilterInvalidPatternNamingStep(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.OutputFilter = []string{"load["}
+	p := NewPipeline(cfg, discardLogger)
+	p.AddStep("load[", func() int { return 1 })
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate = %v, want no issues", err)
+	}
+
+	cfg = NewPipelineConfig()
+	cfg.OutputFilter = []string{"load["}
+	q := NewPipeline(cfg, discardLogger)
+	q.AddStep("load", func() int { return 1 })
+	var validationErr *ValidationError
+	if err := q.Validate(); !errors.As(err, &validationErr) || len(validationErr.Issues) != 1 {
+		t.Errorf("Validate = %v, want one issue for the invalid pattern", err)
+	}
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	ganttWidth     = 960
+	ganttLabelW    = 180
+	ganttRowHeight = 28
+	ganttBarHeight = 18
+)
+
+type ganttBar struct {
+	Name       string
+	X, Y, W    float64
+	Class      string
+	Annotation string
+}
+
+type ganttLink struct {
+	X1, Y1, X2, Y2 float64
+}
+
+type ganttData struct {
+	Title  string
+	Width  int
+	Height int
+	Bars   []ganttBar
+	Links  []ganttLink
+	Total  time.Duration
+}
+
+var ganttTemplate = template.Must(template.New("gantt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; }
+.ok { fill: #4c9a2a; } .failed { fill: #c0392b; } .skipped { fill: #bbb; }
+.resumed { fill: #2a6f9a; } .cached { fill: #8e44ad; }
+.link { stroke: #888; stroke-width: 1; fill: none; marker-end: url(#arrow); }
+text.note { fill: #555; font-size: 11px; }
+</style>
+</head>
+<body>
+<h3>{{.Title}} ({{.Total}})</h3>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}">
+<defs><marker id="arrow" viewBox="0 0 6 6" refX="6" refY="3" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L6,3 L0,6 z" fill="#888"/></marker></defs>
+{{range .Bars}}<text x="4" y="{{.Y}}" dy="13">{{.Name}}</text>
+<rect class="{{.Class}}" x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="18"><title>{{.Name}}: {{.Annotation}}</title></rect>
+<text class="note" x="{{.X}}" y="{{.Y}}" dx="{{.W}}" dy="13" transform="translate(4,0)">{{.Annotation}}</text>
+{{end}}{{range .Links}}<path class="link" d="M{{.X1}},{{.Y1}} C{{.X2}},{{.Y1}} {{.X1}},{{.Y2}} {{.X2}},{{.Y2}}"/>
+{{end}}</svg>
+</body>
+</html>
+`))
+
+// WriteGanttHTML writes a standalone HTML page with an SVG Gantt chart of the run.
+// If g is not nil, data dependencies between steps are drawn as arrows.
+func (r *Result) WriteGanttHTML(w io.Writer, title string, g *Graph) error {
+	data := ganttData{
+		Title:  title,
+		Width:  ganttWidth,
+		Height: ganttRowHeight*len(r.Steps) + 10,
+		Total:  r.Duration(),
+	}
+
+	var origin time.Time
+	if len(r.Steps) > 0 {
+		origin = r.Steps[0].Start
+	}
+	scale := float64(ganttWidth-ganttLabelW-120) / float64(max(r.Duration(), time.Microsecond))
+
+	rows := make(map[string]int, len(r.Steps))
+	for i, step := range r.Steps {
+		bar := ganttBar{
+			Name:       step.Name,
+			X:          ganttLabelW + float64(step.Start.Sub(origin))*scale,
+			Y:          float64(i*ganttRowHeight + 5),
+			W:          max(float64(step.Duration)*scale, 2),
+			Class:      "ok",
+			Annotation: step.Duration.String(),
+		}
+		var notes []string
+		switch {
+		case step.Skipped:
+			bar.Class = "skipped"
+			notes = append(notes, "skipped")
+		case step.Resumed:
+			bar.Class = "resumed"
+			notes = append(notes, "resumed from checkpoint")
+		case step.Cached:
+			bar.Class = "cached"
+			notes = append(notes, "cached")
+		}
+		if step.Err != nil {
+			bar.Class = "failed"
+			notes = append(notes, step.Err.Error())
+		}
+		if len(notes) > 0 {
+			bar.Annotation += " — " + strings.Join(notes, ", ")
+		}
+		rows[step.Name] = len(data.Bars)
+		data.Bars = append(data.Bars, bar)
+	}
+
+	if g != nil {
+		for _, e := range g.Edges {
+			from, okFrom := rows[strings.TrimPrefix(e.From, "step:")]
+			to, okTo := rows[strings.TrimPrefix(e.To, "step:")]
+			if !okFrom || !okTo || !strings.HasPrefix(e.From, "step:") || from == to {
+				continue
+			}
+			src, dst := data.Bars[from], data.Bars[to]
+			data.Links = append(data.Links, ganttLink{
+				X1: src.X + src.W, Y1: src.Y + ganttBarHeight/2,
+				X2: dst.X, Y2: dst.Y + ganttBarHeight/2,
+			})
+		}
+	}
+	return ganttTemplate.Execute(w, data)
+}
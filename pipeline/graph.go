@@ -0,0 +1,179 @@
+package pipeline
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type GraphNodeKind string
+
+const (
+	GraphNodeStep    GraphNodeKind = "step"
+	GraphNodeAdapter GraphNodeKind = "adapter" // step inserted to convert a value, see ConverterRegistry
+	GraphNodeInput   GraphNodeKind = "input"
+	GraphNodeLiteral GraphNodeKind = "literal"
+	GraphNodeEnv     GraphNodeKind = "env"
+)
+
+type GraphNode struct {
+	ID    string
+	Kind  GraphNodeKind
+	Label string
+}
+
+// GraphEdge connects the source of a value to the step parameter it is bound to.
+type GraphEdge struct {
+	From  string
+	To    string
+	Param int
+	Label string
+}
+
+// Graph is the data flow between steps as it would be resolved by Execute.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// Graph builds the dependency graph of the pipeline from step signatures and bindings.
+// Steps appear in execution order; parameters that cannot be resolved have no edge.
+func (p *Pipeline) Graph() *Graph {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	g := &Graph{}
+	names := make(map[int]string)
+	for name, i := range p.context.inputNames {
+		names[i] = name
+	}
+	for i, v := range p.context.InitialValues() {
+		label := fmt.Sprintf("input %d (%s)", i, v.Type())
+		if name, ok := names[i]; ok {
+			label = fmt.Sprintf("input %s (%s)", name, v.Type())
+		}
+		g.Nodes = append(g.Nodes, GraphNode{ID: fmt.Sprintf("input:%d", i), Kind: GraphNodeInput, Label: label})
+	}
+	steps, adapters := p.plannedSteps()
+	for _, step := range steps {
+		kind := GraphNodeStep
+		if adapters[step.Name] {
+			kind = GraphNodeAdapter
+		}
+		g.Nodes = append(g.Nodes, GraphNode{ID: "step:" + step.Name, Kind: kind, Label: step.Name})
+	}
+
+	_, resolutions := p.simulate()
+	for _, res := range resolutions {
+		to := "step:" + res.Step
+		label := res.Type.String()
+		for _, ref := range res.Collected {
+			g.Edges = append(g.Edges, GraphEdge{From: ref.nodeID(), To: to, Param: res.Param, Label: label})
+		}
+		switch {
+		case res.From != nil && res.From.Step == "":
+			g.Edges = append(g.Edges, GraphEdge{From: fmt.Sprintf("input:%d", res.From.Index), To: to, Param: res.Param, Label: label})
+		case res.From != nil:
+			if names := p.outputNames(res.From.Step); res.From.Index < len(names) {
+				label = names[res.From.Index] + ": " + label
+			}
+			g.Edges = append(g.Edges, GraphEdge{From: "step:" + res.From.Step, To: to, Param: res.Param, Label: label})
+		case res.Binding.Source == ArgSourceLiteral:
+			id := fmt.Sprintf("literal:%s:%d", res.Step, res.Param)
+			g.Nodes = append(g.Nodes, GraphNode{ID: id, Kind: GraphNodeLiteral, Label: fmt.Sprintf("%v", res.Binding.Value)})
+			g.Edges = append(g.Edges, GraphEdge{From: id, To: to, Param: res.Param, Label: label})
+		case res.Binding.Source == ArgSourceEnv:
+			id := "env:" + res.Binding.Name
+			if !g.hasNode(id) {
+				g.Nodes = append(g.Nodes, GraphNode{ID: id, Kind: GraphNodeEnv, Label: "$" + res.Binding.Name})
+			}
+			g.Edges = append(g.Edges, GraphEdge{From: id, To: to, Param: res.Param, Label: label})
+		}
+	}
+	return g
+}
+
+func (r valueRef) nodeID() string {
+	if r.Step == "" {
+		return fmt.Sprintf("input:%d", r.Index)
+	}
+	return "step:" + r.Step
+}
+
+func (p *Pipeline) outputNames(stepName string) []string {
+	if stepCfg, ok := p.config.StepConfigs[stepName]; ok {
+		return stepCfg.OutputNames
+	}
+	return nil
+}
+
+func (g *Graph) hasNode(id string) bool {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeAliases assigns short identifiers that are safe to use in DOT and Mermaid.
+func (g *Graph) nodeAliases() map[string]string {
+	aliases := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		aliases[n.ID] = fmt.Sprintf("n%d", i)
+	}
+	return aliases
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	aliases := g.nodeAliases()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph pipeline {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	for _, n := range g.Nodes {
+		shape := "box"
+		switch n.Kind {
+		case GraphNodeInput:
+			shape = "ellipse"
+		case GraphNodeLiteral, GraphNodeEnv:
+			shape = "note"
+		case GraphNodeAdapter:
+			shape = "box, style=dashed"
+		}
+		fmt.Fprintf(bw, "  %s [label=%q, shape=%s];\n", aliases[n.ID], n.Label, shape)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  %s -> %s [label=%q];\n", aliases[e.From], aliases[e.To], e.Label)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	aliases := g.nodeAliases()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "flowchart LR")
+	for _, n := range g.Nodes {
+		label := mermaidEscape(n.Label)
+		switch n.Kind {
+		case GraphNodeInput:
+			fmt.Fprintf(bw, "  %s([\"%s\"])\n", aliases[n.ID], label)
+		case GraphNodeLiteral, GraphNodeEnv:
+			fmt.Fprintf(bw, "  %s>\"%s\"]\n", aliases[n.ID], label)
+		case GraphNodeAdapter:
+			fmt.Fprintf(bw, "  %s[/\"%s\"/]\n", aliases[n.ID], label)
+		default:
+			fmt.Fprintf(bw, "  %s[\"%s\"]\n", aliases[n.ID], label)
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  %s -->|\"%s\"| %s\n", aliases[e.From], mermaidEscape(e.Label), aliases[e.To])
+	}
+	return bw.Flush()
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat reports a step still executing; see PipelineConfig.HeartbeatInterval.
+type Heartbeat struct {
+	Pipeline string // PipelineConfig.Name
+	RunID    string
+	Step     string
+	Elapsed  time.Duration // Since the step started.
+	// Waiting tells that the step is still waiting for its StepConfig.WaitFor
+	// condition rather than running.
+	Waiting bool
+}
+
+// heartbeat emits the heartbeats of one step execution until stopped.
+type heartbeat struct {
+	waiting atomic.Bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// startHeartbeat starts the heartbeats of step, if it has an interval; the
+// returned heartbeat, possibly nil, must be stopped when the step returns.
+func (p *Pipeline) startHeartbeat(step Step) *heartbeat {
+	interval := p.config.HeartbeatInterval
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok && stepCfg.HeartbeatInterval != 0 {
+		interval = stepCfg.HeartbeatInterval
+	}
+	if interval <= 0 {
+		return nil
+	}
+	// p.logger changes with the step running, and the callback runs on the heartbeat's goroutine.
+	logger, onHeartbeat := p.logger, p.config.OnHeartbeat
+	beat := Heartbeat{Pipeline: p.config.Name, RunID: p.currentRunID, Step: step.Name}
+	hb := &heartbeat{done: make(chan struct{})}
+	start := time.Now()
+	hb.wg.Add(1)
+	go func() {
+		defer hb.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hb.done:
+				return
+			case <-ticker.C:
+			}
+			beat.Elapsed, beat.Waiting = time.Since(start), hb.waiting.Load()
+			switch {
+			case onHeartbeat != nil:
+				onHeartbeat(beat)
+			case beat.Waiting:
+				logger.Infof("Step %q still waiting for its condition after %s", step.Name, beat.Elapsed.Round(time.Millisecond))
+			default:
+				logger.Infof("Step %q still running after %s", step.Name, beat.Elapsed.Round(time.Millisecond))
+			}
+		}
+	}()
+	return hb
+}
+
+func (hb *heartbeat) setWaiting(waiting bool) {
+	if hb != nil {
+		hb.waiting.Store(waiting)
+	}
+}
+
+// stop ends the heartbeats, once the last one has been emitted.
+func (hb *heartbeat) stop() {
+	if hb != nil {
+		close(hb.done)
+		hb.wg.Wait()
+	}
+}
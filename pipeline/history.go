@@ -0,0 +1,223 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// RunStatus is the state of a run recorded in a RunRegistry.
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+)
+
+// RunInfo is the metadata a RunRegistry keeps about one execution.
+type RunInfo struct {
+	ID       string        `json:"id"`
+	Pipeline string        `json:"pipeline,omitempty"` // PipelineConfig.Name
+	Status   RunStatus     `json:"status"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`    // Zero while the run is in progress.
+	Inputs   []string      `json:"inputs"` // Type and abbreviated value of each initial input.
+	Steps    []RunStepInfo `json:"steps"`
+	Err      string        `json:"error,omitempty"` // Message of the error returned by the run, if any.
+	Code     ErrorCode     `json:"code,omitempty"`
+
+	// ChainID and TriggeredBy are the run IDs of the first run of the chain and of
+	// the run that triggered this one, for runs started by PipelineConfig.Triggers.
+	ChainID     string `json:"chain_id,omitempty"`
+	TriggeredBy string `json:"triggered_by,omitempty"`
+}
+
+// RunStepInfo summarizes a step record of a run.
+type RunStepInfo struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"` // See StepRecord.Status.
+	Duration time.Duration `json:"duration_ns"`
+	Err      string        `json:"error,omitempty"`
+}
+
+// RunRegistry keeps the metadata of the latest runs of the pipelines configured
+// with it, so that past runs can be inspected without external infrastructure.
+// It is safe for concurrent use and may be shared by several pipelines.
+type RunRegistry struct {
+	mu    sync.Mutex
+	limit int
+	runs  []*RunInfo // oldest first
+}
+
+// NewRunRegistry creates a registry keeping the last limit runs, or every run if
+// limit is not positive.
+func NewRunRegistry(limit int) *RunRegistry {
+	return &RunRegistry{limit: limit}
+}
+
+// Runs returns the recorded runs, oldest first.
+func (r *RunRegistry) Runs() []RunInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	runs := make([]RunInfo, len(r.runs))
+	for i, run := range r.runs {
+		runs[i] = *run
+	}
+	return runs
+}
+
+// Chain returns the runs of the chain of triggered runs id belongs to, in start
+// order, starting with the first run of the chain if it is still recorded.
+func (r *RunRegistry) Chain(id string) []RunInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	root := id
+	for _, run := range r.runs {
+		if run.ID == id && run.ChainID != "" {
+			root = run.ChainID
+		}
+	}
+	var chain []RunInfo
+	for _, run := range r.runs {
+		if run.ID == root || run.ChainID == root {
+			chain = append(chain, *run)
+		}
+	}
+	return chain
+}
+
+// Run returns the run with the given ID, if it is still recorded.
+func (r *RunRegistry) Run(id string) (RunInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.ID == id {
+			return *run, true
+		}
+	}
+	return RunInfo{}, false
+}
+
+func (r *RunRegistry) begin(run *RunInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, run)
+	if r.limit > 0 && len(r.runs) > r.limit {
+		r.runs = append(r.runs[:0:0], r.runs[len(r.runs)-r.limit:]...)
+	}
+}
+
+func (r *RunRegistry) finish(id string, result *Result, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.ID == id {
+			run.complete(result, err)
+			return
+		}
+	}
+	// evicted while running
+}
+
+// complete records the outcome of the run.
+func (run *RunInfo) complete(result *Result, err error) {
+	run.End = time.Now()
+	run.Status = RunStatusSucceeded
+	if err != nil {
+		run.Status, run.Err, run.Code = RunStatusFailed, err.Error(), ErrorCodeOf(err)
+	}
+	for _, step := range result.Steps {
+		info := RunStepInfo{Name: step.Name, Status: step.Status(), Duration: step.Duration}
+		if step.Err != nil {
+			info.Err = step.Err.Error()
+		}
+		run.Steps = append(run.Steps, info)
+	}
+}
+
+// Runs returns the runs of this pipeline recorded in PipelineConfig.RunRegistry,
+// oldest first, or nil if it has none.
+func (p *Pipeline) Runs() []RunInfo {
+	if p.config.RunRegistry == nil {
+		return nil
+	}
+	var runs []RunInfo
+	for _, run := range p.config.RunRegistry.Runs() {
+		if run.Pipeline == p.config.Name {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+// newRunInfo describes a run starting now.
+func (p *Pipeline) newRunInfo(ctx context.Context, id string) *RunInfo {
+	run := &RunInfo{ID: id, Pipeline: p.config.Name, Status: RunStatusRunning, Start: time.Now()}
+	if link, ok := chainFrom(ctx); ok {
+		run.ChainID, run.TriggeredBy = link.root, link.parent
+	}
+	for _, v := range p.context.InitialValues() {
+		run.Inputs = append(run.Inputs, inputSummary(p.config.TypeRegistry.Name(v.Type()), v))
+	}
+	return run
+}
+
+// beginRun records the start of a run in the configured RunRegistry, if any.
+func (p *Pipeline) beginRun(ctx context.Context, id string) {
+	if p.config.RunRegistry != nil {
+		p.config.RunRegistry.begin(p.newRunInfo(ctx, id))
+	}
+}
+
+// finishRun records the outcome of a run in the configured RunRegistry, if any.
+func (p *Pipeline) finishRun(result *Result, err error) {
+	if p.config.RunRegistry != nil {
+		p.config.RunRegistry.finish(result.RunID, result, err)
+	}
+}
+
+const inputSummaryLen = 64
+
+// inputSummary describes an initial input by its type name and abbreviated value.
+func inputSummary(typeName string, v reflect.Value) string {
+	s := fmt.Sprintf("%v", v)
+	if len(s) > inputSummaryLen {
+		cut := inputSummaryLen
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut-- // keep the value valid UTF-8
+		}
+		s = s[:cut] + "…"
+	}
+	return fmt.Sprintf("%s(%s)", typeName, s)
+}
+
+type runIDKey struct{}
+
+// WithRunID returns a context making Run use id as the run ID instead of a new
+// one, so that a caller can refer to the run before it finishes.
+func WithRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, id)
+}
+
+// runID returns the run ID given with WithRunID, or a new one.
+func runID(ctx context.Context) string {
+	if id, ok := ctx.Value(runIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	return NewRunID()
+}
+
+// NewRunID returns a new run ID, a random (version 4) UUID, as used when none is
+// given with WithRunID.
+func NewRunID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunReport describes a finished run to the hooks registered with OnFinish.
+type RunReport struct {
+	Run    RunInfo // Status, end time and steps filled in.
+	Result *Result
+	Err    error // Returned by the run; nil if it succeeded.
+}
+
+// OnStart registers fn to be called before the first step of every run, once the
+// preflight checks passed, to open resources shared by the steps or record the
+// start of the run. Hooks are called in registration order; if one fails the run
+// stops with its error and no step runs.
+func (p *Pipeline) OnStart(fn func(ctx context.Context, run RunInfo) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if fn != nil {
+		p.startHooks = append(p.startHooks, fn)
+	}
+}
+
+// OnFinish registers fn to be called when a run is over, after its teardown steps,
+// to release shared resources or flush exporters. Hooks are called in reverse
+// registration order for every run whose OnStart hooks were called, even if one of
+// them failed, with a context that is not cancelled with the run's.
+func (p *Pipeline) OnFinish(fn func(ctx context.Context, report RunReport)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if fn != nil {
+		p.finishHooks = append(p.finishHooks, fn)
+	}
+}
+
+// callStartHooks calls the OnStart hooks and returns the first failure.
+func (p *Pipeline) callStartHooks(ctx context.Context, run *RunInfo) error {
+	for i, fn := range p.startHooks {
+		if err := fn(ctx, *run); err != nil {
+			return fmt.Errorf("start hook %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// callFinishHooks reports the outcome of the run to the OnFinish hooks.
+func (p *Pipeline) callFinishHooks(ctx context.Context, run *RunInfo, result *Result, err error) {
+	if len(p.finishHooks) == 0 {
+		return
+	}
+	report := RunReport{Run: *run, Result: result, Err: err}
+	report.Run.complete(result, err)
+	ctx = context.WithoutCancel(ctx)
+	for i := len(p.finishHooks) - 1; i >= 0; i-- {
+		p.finishHooks[i](ctx, report)
+	}
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+)
+
+func (p *Pipeline) isLazy(step Step) bool {
+	stepCfg, ok := p.config.StepConfigs[step.Name]
+	return ok && stepCfg.Lazy
+}
+
+// runLazyStep runs the deferred lazy step called name, if there is one, so that
+// its outputs can be bound. It is called while another step resolves its arguments,
+// which always happens on the run's goroutine, AutoParallel batches included. A
+// lazy step runs at most once per run: every step requesting it gets the outcome
+// of that single run, so a failure is reported the same way to all of them.
+func (p *Pipeline) runLazyStep(name string) error {
+	if err, done := p.lazyOutcomes[name]; done {
+		return err
+	}
+	step, ok := p.lazySteps[name]
+	if !ok {
+		return nil
+	}
+	delete(p.lazySteps, name)
+	err := p.runLazyStepOnce(step)
+	p.lazyOutcomes[name] = err
+	return err
+}
+
+func (p *Pipeline) runLazyStepOnce(step Step) error {
+	name := step.Name
+	if !p.shouldRun(step) {
+		p.logger.Infof("Skipping lazy step %q: condition not met", name)
+		now := time.Now()
+		p.lazyRecords = append(p.lazyRecords, StepRecord{Name: name, Start: now, End: now, Skipped: true})
+		p.progress(ProgressStepSkipped, name, 0, nil)
+		return withCode(CodeArgNotFound, fmt.Errorf("lazy step %s was skipped by its condition", name))
+	}
+
+	// The requesting step is halfway through resolving its own arguments.
+	pickCounters, logger, conversions := p.pickCounters, p.logger, p.conversions
+	record := p.runStep(step)
+	p.pickCounters, p.logger, p.conversions = pickCounters, logger, conversions
+
+	p.lazyRecords = append(p.lazyRecords, record)
+	if record.Err != nil {
+		return &lazyStepError{name: name, err: record.Err}
+	}
+	return nil
+}
+
+// lazyStepError is returned to the steps requesting a lazy step that failed.
+type lazyStepError struct {
+	name string
+	err  error
+}
+
+func (e *lazyStepError) Error() string {
+	return fmt.Sprintf("lazy step %s: %v", e.name, e.err)
+}
+
+func (e *lazyStepError) Unwrap() error {
+	return e.err
+}
+
+// flushLazyRecords moves the records of lazy steps run on demand into result,
+// ahead of the record of the step that requested them.
+func (p *Pipeline) flushLazyRecords(result *Result) {
+	for _, record := range p.lazyRecords {
+		if record.Err != nil {
+			p.logger.Errorf("Lazy step %q failed: %v", record.Name, record.Err)
+			record.Err = p.stepError(Step{Name: record.Name}, len(result.Steps), record.Err)
+		}
+		result.Steps = append(result.Steps, record)
+	}
+	p.lazyRecords = nil
+}
+
+// skipUnusedLazySteps records the lazy steps no step requested as skipped, in step order.
+func (p *Pipeline) skipUnusedLazySteps(result *Result) {
+	for _, step := range p.steps {
+		if _, ok := p.lazySteps[step.Name]; !ok {
+			continue
+		}
+		p.logger.Infof("Skipping lazy step %q: outputs never requested", step.Name)
+		now := time.Now()
+		result.Steps = append(result.Steps, StepRecord{Name: step.Name, Start: now, End: now, Skipped: true})
+		p.progress(ProgressStepSkipped, step.Name, 0, nil)
+		delete(p.lazySteps, step.Name)
+	}
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func lazyConfig(requesters ...string) *PipelineConfig {
+	cfg := NewPipelineConfig()
+	cfg.StepConfigs["fetch"] = &StepConfig{Lazy: true}
+	for _, name := range requesters {
+		cfg.StepConfigs[name] = &StepConfig{ArgBindings: []*ArgBinding{{Source: ArgSourceFunctionOutput, Name: "fetch"}}}
+	}
+	return cfg
+}
+
+func TestLazyStepNotRequested(t *testing.T) {
+	p := NewPipeline(lazyConfig(), discardLogger)
+	calls := 0
+	p.AddStep("fetch", func() int { calls++; return 1 })
+	p.AddStep("other", func() string { return "x" })
+
+	result, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("fetch ran %d times, want 0", calls)
+	}
+	// Lazy steps never requested are recorded once the other steps are done.
+	if record := result.Steps[len(result.Steps)-1]; record.Name != "fetch" || !record.Skipped {
+		t.Errorf("last record = %+v, want fetch skipped", record)
+	}
+}
+
+func TestLazyStepRunsOncePerRun(t *testing.T) {
+	p := NewPipeline(lazyConfig("first", "second"), discardLogger)
+	calls := 0
+	p.AddStep("fetch", func() int { calls++; return 1 })
+	p.AddStep("first", func(n int) string { return "a" })
+	p.AddStep("second", func(n int) bool { return true })
+
+	for run := 1; run <= 2; run++ {
+		result, err := p.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if calls != run {
+			t.Errorf("after %d runs fetch ran %d times, want %d", run, calls, run)
+		}
+		var names []string
+		for _, record := range result.Steps {
+			names = append(names, record.Name)
+		}
+		if len(names) != 3 || names[0] != "fetch" || names[1] != "first" {
+			t.Errorf("Result.Steps = %v, want fetch recorded before first", names)
+		}
+	}
+}
+
+func TestLazyStepFailureReachesEveryRequester(t *testing.T) {
+	cfg := lazyConfig("first", "second")
+	cfg.ErrorPolicy = ErrorPolicyContinueCollect
+	p := NewPipeline(cfg, discardLogger)
+	errBoom := errors.New("boom")
+	calls := 0
+	p.AddStep("fetch", func() (int, error) { calls++; return 0, errBoom })
+	p.AddStep("first", func(n int) string { return "a" })
+	p.AddStep("second", func(n int) bool { return true })
+
+	result, err := p.Run(context.Background())
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Run = %v, want %v", err, errBoom)
+	}
+	if calls != 1 {
+		t.Errorf("fetch ran %d times, want 1", calls)
+	}
+	for _, record := range result.Steps {
+		if (record.Name == "first" || record.Name == "second") && !errors.Is(record.Err, errBoom) {
+			t.Errorf("record of %s has error %v, want %v", record.Name, record.Err, errBoom)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Logger is the logging interface used by pipelines. *logrus.Logger, *logrus.Entry
+// and *zap.SugaredLogger satisfy it as they are; log/slog loggers can be wrapped
+// with NewSlogLogger.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// LevelSetter is implemented by loggers whose minimum level can be changed
+// through (*Pipeline).SetLogLevel.
+type LevelSetter interface {
+	SetLogLevel(level LogLevel)
+}
+
+// FieldLogger is implemented by loggers that can attach structured fields to every
+// record they emit. Loggers that don't are given the fields as a message prefix.
+type FieldLogger interface {
+	Logger
+	WithFields(keysAndValues ...interface{}) Logger
+}
+
+// SlogLogger adapts a *slog.Logger to Logger. Records below its level are
+// dropped before reaching the handler, which may filter further.
+type SlogLogger struct {
+	logger *slog.Logger
+	level  slog.LevelVar
+}
+
+// NewSlogLogger wraps l, or slog.Default() if l is nil. The adapter starts at
+// LogLevelDebug, leaving filtering to the handler until SetLogLevel is called.
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	s := &SlogLogger{logger: l}
+	s.level.Set(slog.LevelDebug)
+	return s
+}
+
+func (s *SlogLogger) SetLogLevel(level LogLevel) {
+	s.level.Set(slogLevel(level))
+}
+
+func (s *SlogLogger) WithFields(keysAndValues ...interface{}) Logger {
+	w := &SlogLogger{logger: s.logger.With(keysAndValues...)}
+	w.level.Set(s.level.Level())
+	return w
+}
+
+func (s *SlogLogger) Debugf(format string, args ...interface{}) {
+	s.log(slog.LevelDebug, format, args)
+}
+
+func (s *SlogLogger) Infof(format string, args ...interface{}) {
+	s.log(slog.LevelInfo, format, args)
+}
+
+func (s *SlogLogger) Warnf(format string, args ...interface{}) {
+	s.log(slog.LevelWarn, format, args)
+}
+
+func (s *SlogLogger) Errorf(format string, args ...interface{}) {
+	s.log(slog.LevelError, format, args)
+}
+
+func (s *SlogLogger) log(level slog.Level, format string, args []interface{}) {
+	ctx := context.Background()
+	if level < s.level.Level() || !s.logger.Enabled(ctx, level) {
+		return
+	}
+	s.logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
+
+// prefixLogger prepends fields to the messages of a logger without FieldLogger support.
+type prefixLogger struct {
+	Logger
+	prefix string
+}
+
+func (l prefixLogger) Debugf(format string, args ...interface{}) {
+	l.Logger.Debugf(l.prefix+format, args...)
+}
+
+func (l prefixLogger) Infof(format string, args ...interface{}) {
+	l.Logger.Infof(l.prefix+format, args...)
+}
+
+func (l prefixLogger) Warnf(format string, args ...interface{}) {
+	l.Logger.Warnf(l.prefix+format, args...)
+}
+
+func (l prefixLogger) Errorf(format string, args ...interface{}) {
+	l.Logger.Errorf(l.prefix+format, args...)
+}
+
+// withFields attaches alternating keys and values to l.
+func withFields(l Logger, keysAndValues ...interface{}) Logger {
+	if fl, ok := l.(FieldLogger); ok {
+		return fl.WithFields(keysAndValues...)
+	}
+	var prefix strings.Builder
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fmt.Fprintf(&prefix, "%v=%v ", keysAndValues[i], keysAndValues[i+1])
+	}
+	// Escape the prefix so it is not taken as part of the format.
+	return prefixLogger{Logger: l, prefix: strings.ReplaceAll(prefix.String(), "%", "%%")}
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// newDefaultLogger logs text records at info level and above to stderr.
+func newDefaultLogger() Logger {
+	s := NewSlogLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	s.SetLogLevel(LogLevelInfo)
+	return s
+}
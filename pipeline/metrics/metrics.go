@@ -0,0 +1,85 @@
+// Package metrics exposes Prometheus metrics for pipeline step executions.
+package metrics
+
+import (
+	"time"
+
+	"pipeline/pipeline"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	_ pipeline.StepObserver      = (*Collector)(nil)
+	_ pipeline.AssertionObserver = (*Collector)(nil)
+)
+
+// Collector is a pipeline.StepObserver recording step executions, failures,
+// durations and failed assertions, labelled by pipeline and step name.
+type Collector struct {
+	executions *prometheus.CounterVec
+	failures   *prometheus.CounterVec
+	assertions *prometheus.CounterVec
+	durations  *prometheus.HistogramVec
+	inFlight   *prometheus.GaugeVec
+}
+
+// New creates a Collector and registers its metrics with reg.
+// If reg is nil, prometheus.DefaultRegisterer is used.
+func New(reg prometheus.Registerer) (*Collector, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	labels := []string{"pipeline", "step"}
+	c := &Collector{
+		executions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pipeline",
+			Name:      "step_executions_total",
+			Help:      "Number of step executions.",
+		}, labels),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pipeline",
+			Name:      "step_failures_total",
+			Help:      "Number of step executions that returned an error.",
+		}, labels),
+		assertions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pipeline",
+			Name:      "assertion_failures_total",
+			Help:      "Number of assertion steps that reported a data quality failure.",
+		}, labels),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "pipeline",
+			Name:      "step_duration_seconds",
+			Help:      "Duration of step executions.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pipeline",
+			Name:      "steps_in_flight",
+			Help:      "Number of steps currently executing.",
+		}, labels),
+	}
+	for _, m := range []prometheus.Collector{c.executions, c.failures, c.assertions, c.durations, c.inFlight} {
+		if err := reg.Register(m); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *Collector) StepStarted(pipelineName, step string) {
+	c.inFlight.WithLabelValues(pipelineName, step).Inc()
+}
+
+func (c *Collector) StepFinished(pipelineName, step string, duration time.Duration, err error) {
+	c.inFlight.WithLabelValues(pipelineName, step).Dec()
+	c.executions.WithLabelValues(pipelineName, step).Inc()
+	c.durations.WithLabelValues(pipelineName, step).Observe(duration.Seconds())
+	if err != nil {
+		c.failures.WithLabelValues(pipelineName, step).Inc()
+	}
+}
+
+func (c *Collector) AssertionFailed(pipelineName, step string, err error) {
+	c.assertions.WithLabelValues(pipelineName, step).Inc()
+}
@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStepNotFound is returned when a step referenced by name has not been added.
+var ErrStepNotFound = errors.New("step not found")
+
+// RemoveStep removes the step registered under name. Its StepConfig, if any, is kept.
+func (p *Pipeline) RemoveStep(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i, err := p.stepIndex(name)
+	if err != nil {
+		return err
+	}
+	p.steps = append(p.steps[:i:i], p.steps[i+1:]...)
+	p.logger.Debugf("Removed step %q", name)
+	return nil
+}
+
+// ReplaceStep swaps the callable of the step registered under name, keeping its
+// position and configuration.
+func (p *Pipeline) ReplaceStep(name string, callable interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i, err := p.stepIndex(name)
+	if err != nil {
+		return err
+	}
+	p.steps[i].Callable = callable
+	p.logger.Debugf("Replaced step %q", name)
+	return nil
+}
+
+// InsertStepBefore adds a step right before the step registered under ref.
+func (p *Pipeline) InsertStepBefore(ref, name string, callable interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i, err := p.stepIndex(ref)
+	if err != nil {
+		return err
+	}
+	p.insertStep(i, Step{Name: name, Callable: callable})
+	p.logger.Debugf("Inserted step %q before %q", name, ref)
+	return nil
+}
+
+// InsertStepAfter adds a step right after the step registered under ref.
+func (p *Pipeline) InsertStepAfter(ref, name string, callable interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	i, err := p.stepIndex(ref)
+	if err != nil {
+		return err
+	}
+	p.insertStep(i+1, Step{Name: name, Callable: callable})
+	p.logger.Debugf("Inserted step %q after %q", name, ref)
+	return nil
+}
+
+// DisableStep disables the step, teardown step or assert step registered under
+// name, as if its StepConfig.Disabled were set, until EnableStep is called.
+func (p *Pipeline) DisableStep(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hasStep(name) {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, name)
+	}
+	if p.disabledSteps == nil {
+		p.disabledSteps = make(map[string]bool)
+	}
+	p.disabledSteps[name] = true
+	p.logger.Debugf("Disabled step %q", name)
+	return nil
+}
+
+// EnableStep reverts DisableStep. A step disabled by its StepConfig stays disabled.
+func (p *Pipeline) EnableStep(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.hasStep(name) {
+		return fmt.Errorf("%w: %s", ErrStepNotFound, name)
+	}
+	delete(p.disabledSteps, name)
+	p.logger.Debugf("Enabled step %q", name)
+	return nil
+}
+
+func (p *Pipeline) isDisabled(name string) bool {
+	stepCfg, ok := p.config.StepConfigs[name]
+	return p.disabledSteps[name] || (ok && stepCfg.Disabled) || p.deselected(name)
+}
+
+// hasStep reports whether a step, teardown step or assert step is registered under name.
+func (p *Pipeline) hasStep(name string) bool {
+	for _, steps := range [][]Step{p.steps, p.teardownSteps, p.assertSteps} {
+		for _, step := range steps {
+			if step.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stepIndex returns the position of the first step registered under name.
+func (p *Pipeline) stepIndex(name string) (int, error) {
+	for i, step := range p.steps {
+		if step.Name == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: %s", ErrStepNotFound, name)
+}
+
+func (p *Pipeline) insertStep(i int, step Step) {
+	p.steps = append(p.steps[:i:i], append([]Step{step}, p.steps[i:]...)...)
+}
@@ -0,0 +1,269 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// notifyTimeout bounds each notifier call, which is made after the run is over.
+const notifyTimeout = 10 * time.Second
+
+// Notification describes a finished run for Notifiers.
+type Notification struct {
+	Pipeline string // PipelineConfig.Name
+	RunID    string
+	Err      error // nil if the run succeeded
+	Result   *Result
+}
+
+// Notifier is told about every finished run of the pipelines configured with it,
+// or only about failed ones with PipelineConfig.NotifyFailuresOnly.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// notify sends the outcome of the run to the configured notifiers. Their failures
+// are logged, never returned: they must not change the outcome of the run.
+func (p *Pipeline) notify(ctx context.Context, result *Result, err error) {
+	if len(p.config.Notifiers) == 0 || (err == nil && p.config.NotifyFailuresOnly) {
+		return
+	}
+	n := Notification{Pipeline: p.config.Name, RunID: result.RunID, Err: err, Result: result}
+	// The run's context may be the reason it failed.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+	for _, notifier := range p.config.Notifiers {
+		if notifyErr := notifier.Notify(ctx, n); notifyErr != nil {
+			p.logger.Warnf("Notifier %T failed: %v", notifier, notifyErr)
+		}
+	}
+}
+
+// NotificationPayload is the JSON body posted by WebhookNotifier.
+type NotificationPayload struct {
+	Pipeline string            `json:"pipeline,omitempty"`
+	RunID    string            `json:"run_id"`
+	Status   RunStatus         `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	Code     ErrorCode         `json:"code,omitempty"`
+	Duration time.Duration     `json:"duration_ns"`
+	Steps    []StepPayloadInfo `json:"steps"`
+}
+
+// StepPayloadInfo is the part of NotificationPayload describing one step record.
+type StepPayloadInfo struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+	Code     ErrorCode     `json:"code,omitempty"`
+}
+
+func newNotificationPayload(n Notification) NotificationPayload {
+	payload := NotificationPayload{
+		Pipeline: n.Pipeline,
+		RunID:    n.RunID,
+		Status:   RunStatusSucceeded,
+		Duration: n.Result.Duration(),
+		Steps:    []StepPayloadInfo{},
+	}
+	if n.Err != nil {
+		payload.Status, payload.Error, payload.Code = RunStatusFailed, n.Err.Error(), ErrorCodeOf(n.Err)
+	}
+	for _, step := range n.Result.Steps {
+		info := StepPayloadInfo{Name: step.Name, Status: step.Status(), Duration: step.Duration}
+		if step.Err != nil {
+			info.Error, info.Code = step.Err.Error(), ErrorCodeOf(step.Err)
+		}
+		payload.Steps = append(payload.Steps, info)
+	}
+	return payload
+}
+
+// WebhookNotifier posts a NotificationPayload as JSON to URL.
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string // Extra request headers, such as Authorization.
+	Client  *http.Client      // http.DefaultClient if nil.
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(newNotificationPayload(n))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return postJSON(ctx, w.Client, w.URL, w.Headers, body)
+}
+
+// SlackNotifier posts a one-message summary of the run to a Slack incoming webhook.
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client // http.DefaultClient if nil.
+}
+
+func (s *SlackNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(map[string]string{"text": notificationText(n, "*")})
+	if err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return postJSON(ctx, s.Client, s.WebhookURL, nil, body)
+}
+
+// EmailNotifier sends a plain-text summary of the run by SMTP, using STARTTLS when
+// the server offers it.
+type EmailNotifier struct {
+	Addr string    // SMTP server as host:port.
+	Auth smtp.Auth // May be nil.
+	From string
+	To   []string
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	for _, addr := range append([]string{e.From}, e.To...) {
+		if strings.ContainsAny(addr, "\r\n") {
+			return fmt.Errorf("email: address %q contains a line break", addr)
+		}
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\n", e.From, strings.Join(e.To, ", "))
+	// The subject holds the pipeline name and the run ID, which callers choose.
+	subject := strings.Join(strings.FieldsFunc(notificationSubject(n), func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\nContent-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(notificationText(n, ""), "\n", "\r\n"))
+	if err := sendMail(ctx, e.Addr, e.Auth, e.From, e.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
+
+// sendMail is smtp.SendMail bounded by ctx: the connection is closed once ctx is done.
+func sendMail(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return contextError(ctx, err)
+	}
+	defer c.Close()
+	if err := smtpSend(c, host, auth, from, to, msg); err != nil {
+		return contextError(ctx, err)
+	}
+	return nil
+}
+
+func smtpSend(c *smtp.Client, host string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// contextError returns ctx's error in place of err if ctx is done, as a closed
+// connection is then the consequence, not the cause, of the failure.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w (%v)", ctxErr, err)
+	}
+	return err
+}
+
+func notificationSubject(n Notification) string {
+	name := n.Pipeline
+	if name == "" {
+		name = "pipeline"
+	}
+	if n.Err != nil {
+		return fmt.Sprintf("%s run %s failed", name, n.RunID)
+	}
+	return fmt.Sprintf("%s run %s succeeded", name, n.RunID)
+}
+
+// notificationText summarizes the run with its failed steps; bold wraps the
+// headline (e.g. "*" for Slack).
+func notificationText(n Notification, bold string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s%s in %s", bold, notificationSubject(n), bold, n.Result.Duration())
+	if n.Err != nil {
+		fmt.Fprintf(&b, "\n%s: %v", ErrorCodeOf(n.Err), n.Err)
+	}
+	for _, step := range n.Result.Steps {
+		if step.Err != nil {
+			fmt.Fprintf(&b, "\n- %v", step.Err) // a *StepError naming the step
+		}
+	}
+	return b.String()
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("POST %s: %s", url, resp.Status)
+	}
+	return nil
+}
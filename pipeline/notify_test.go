@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts one SMTP session on a local port and sends the DATA it
+// received on the returned channel.
+func fakeSMTPServer(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				body, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				data <- string(body)
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 unknown")
+			}
+		}
+	}()
+	return ln.Addr().String(), data
+}
+
+func TestEmailNotifierMessage(t *testing.T) {
+	addr, data := fakeSMTPServer(t)
+	notifier := &EmailNotifier{Addr: addr, From: "pipeline@example.com", To: []string{"ops@example.com"}}
+	n := Notification{Pipeline: "etl", RunID: "run-1\r\nBcc: victim@example.com", Result: &Result{}}
+
+	if err := notifier.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(<-data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Get("Subject"); got != "etl run run-1 Bcc: victim@example.com succeeded" {
+		t.Errorf("Subject = %q", got)
+	}
+	if msg.Get("Bcc") != "" {
+		t.Error("the run ID injected a Bcc header")
+	}
+	for _, header := range []string{"Date", "MIME-Version", "Content-Type"} {
+		if msg.Get(header) == "" {
+			t.Errorf("message has no %s header", header)
+		}
+	}
+}
+
+func TestEmailNotifierRejectsLineBreakInAddress(t *testing.T) {
+	notifier := &EmailNotifier{Addr: "127.0.0.1:1", From: "pipeline@example.com", To: []string{"ops@example.com\r\nBcc: x@example.com"}}
+	if err := notifier.Notify(context.Background(), Notification{Result: &Result{}}); err == nil {
+		t.Error("Notify succeeded, want an error for the line break")
+	}
+}
+
+func TestEmailNotifierHonorsContext(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		// Accept the connection but never greet the client.
+		conn, err := ln.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+	notifier := &EmailNotifier{Addr: ln.Addr().String(), From: "pipeline@example.com", To: []string{"ops@example.com"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = notifier.Notify(ctx, Notification{Result: &Result{}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Notify = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Notify returned after %s, want it bounded by the context", elapsed)
+	}
+}
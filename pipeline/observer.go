@@ -0,0 +1,46 @@
+package pipeline
+
+import "time"
+
+// StepObserver is notified around every step execution, e.g. to record metrics.
+type StepObserver interface {
+	StepStarted(pipeline, step string)
+	StepFinished(pipeline, step string, duration time.Duration, err error)
+}
+
+// AssertionObserver may be implemented by a StepObserver to be told about failed
+// assertion steps (see AddAssertStep) separately from step failures.
+type AssertionObserver interface {
+	AssertionFailed(pipeline, step string, err error)
+}
+
+// AddObserver registers an observer notified for every step executed by the pipeline.
+func (p *Pipeline) AddObserver(o StepObserver) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if o != nil {
+		p.observers = append(p.observers, o)
+	}
+}
+
+func (p *Pipeline) notifyStepStarted(step Step) {
+	for _, o := range p.observers {
+		o.StepStarted(p.config.Name, step.Name)
+	}
+	p.progress(ProgressStepStarted, step.Name, 0, nil)
+}
+
+func (p *Pipeline) notifyStepFinished(step Step, duration time.Duration, err error) {
+	for _, o := range p.observers {
+		o.StepFinished(p.config.Name, step.Name, duration, err)
+	}
+	p.progress(ProgressStepFinished, step.Name, duration, err)
+}
+
+func (p *Pipeline) notifyAssertionFailed(step Step, err error) {
+	for _, o := range p.observers {
+		if ao, ok := o.(AssertionObserver); ok {
+			ao.AssertionFailed(p.config.Name, step.Name, err)
+		}
+	}
+}
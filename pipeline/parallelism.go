@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// Parallelism is injected into step parameters of this type, unless they have an
+// explicit ArgBinding. It holds the step's StepConfig.Parallelism, clamped to
+// [1, GOMAXPROCS], and tells compute-heavy steps how many goroutines to use.
+type Parallelism int
+
+var parallelismType = reflect.TypeOf(Parallelism(0))
+
+// stepParallelism returns the parallelism declared for step, or 1 if none is.
+func (p *Pipeline) stepParallelism(step Step) Parallelism {
+	n := 1
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok && stepCfg.Parallelism > 1 {
+		n = stepCfg.Parallelism
+	}
+	if max := runtime.GOMAXPROCS(0); n > max {
+		n = max
+	}
+	return Parallelism(n)
+}
+
+// reserveCPUs blocks until the parallelism declared for step is available in the
+// process-wide CPU budget and returns a function releasing it. Steps that declare
+// no parallelism do not take part in the budget.
+func (p *Pipeline) reserveCPUs(step Step) (release func()) {
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; !ok || stepCfg.Parallelism < 1 {
+		return func() {}
+	}
+	n := int(p.stepParallelism(step))
+	cpus.acquire(n)
+	return func() { cpus.release(n) }
+}
+
+// cpuBudget counts the CPUs reserved by running steps against GOMAXPROCS.
+type cpuBudget struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	used int
+}
+
+var cpus = newCPUBudget()
+
+func newCPUBudget() *cpuBudget {
+	b := &cpuBudget{}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+func (b *cpuBudget) acquire(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// A step may always start alone, even if GOMAXPROCS shrank since n was computed.
+	for b.used > 0 && b.used+n > runtime.GOMAXPROCS(0) {
+		b.cond.Wait()
+	}
+	b.used += n
+}
+
+func (b *cpuBudget) release(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+	b.cond.Broadcast()
+}
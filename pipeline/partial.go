@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunOnly is like Run but runs only the named main steps; the others are skipped
+// like disabled steps. The outputs the named steps need from skipped steps must be
+// supplied as inputs or be checkpointed: partial runs restore, even without Resume,
+// the checkpoints of the steps skipped before the last step they run, and keep the
+// checkpoints when they succeed. Teardown and assert steps run as usual.
+func (p *Pipeline) RunOnly(ctx context.Context, steps []string, inputs ...interface{}) (*Result, error) {
+	return p.runPartial(ctx, inputs, func(ordered []Step) (map[string]bool, error) {
+		selected := make(map[string]bool, len(steps))
+		for _, name := range steps {
+			if stepPosition(ordered, name) < 0 {
+				return nil, fmt.Errorf("run only: %w: %s", ErrStepNotFound, name)
+			}
+			selected[name] = true
+		}
+		return selected, nil
+	})
+}
+
+// RunFrom is like RunOnly with step and the main steps after it in the effective
+// step order: the steps upstream of it are restored from their checkpoints if they
+// have one, and skipped otherwise.
+func (p *Pipeline) RunFrom(ctx context.Context, step string, inputs ...interface{}) (*Result, error) {
+	return p.runPartial(ctx, inputs, func(ordered []Step) (map[string]bool, error) {
+		i := stepPosition(ordered, step)
+		if i < 0 {
+			return nil, fmt.Errorf("run from: %w: %s", ErrStepNotFound, step)
+		}
+		return stepNames(ordered[i:]), nil
+	})
+}
+
+// RunUntil is like RunOnly with step and the main steps before it in the effective
+// step order, so that the run stops after step.
+func (p *Pipeline) RunUntil(ctx context.Context, step string, inputs ...interface{}) (*Result, error) {
+	return p.runPartial(ctx, inputs, func(ordered []Step) (map[string]bool, error) {
+		i := stepPosition(ordered, step)
+		if i < 0 {
+			return nil, fmt.Errorf("run until: %w: %s", ErrStepNotFound, step)
+		}
+		return stepNames(ordered[:i+1]), nil
+	})
+}
+
+// runPartial runs a snapshot of the pipeline restricted to the main steps chosen
+// by selectSteps among the steps in their effective order.
+func (p *Pipeline) runPartial(ctx context.Context, inputs []interface{}, selectSteps func([]Step) (map[string]bool, error)) (*Result, error) {
+	run := p.newRun(inputs)
+	ordered := run.orderedSteps()
+	selected, err := selectSteps(ordered)
+	if err != nil {
+		return &Result{RunID: runID(ctx)}, err
+	}
+	// Steps left out before the last selected one are upstream of it.
+	last := 0
+	for i, step := range ordered {
+		if selected[step.Name] {
+			last = i
+		}
+	}
+	for _, step := range ordered[:last] {
+		if !selected[step.Name] {
+			selected[step.Name] = false
+		}
+	}
+	run.partial = selected
+	return run.execute(ctx)
+}
+
+// unselected reports whether the main step name is left out of a partial run.
+func (p *Pipeline) unselected(name string) bool {
+	return p.partial != nil && !p.partial[name] && stepPosition(p.steps, name) >= 0
+}
+
+// upstream reports whether the main step name is left out of a partial run but
+// precedes a step it runs, so that its checkpoint is restored.
+func (p *Pipeline) upstream(name string) bool {
+	selected, ok := p.partial[name]
+	return ok && !selected
+}
+
+func stepPosition(steps []Step, name string) int {
+	for i, step := range steps {
+		if step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func stepNames(steps []Step) map[string]bool {
+	names := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		names[step.Name] = true
+	}
+	return names
+}
@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+)
+
+type PartitionGranularity int
+
+const (
+	PartitionHourly PartitionGranularity = iota
+	PartitionDaily
+)
+
+// Partition is the time window [Start, End) a run processes. It is passed to
+// steps as an initial input, so any step taking a Partition parameter receives it.
+type Partition struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (pt Partition) String() string {
+	return fmt.Sprintf("[%s, %s)", pt.Start.Format(time.RFC3339), pt.End.Format(time.RFC3339))
+}
+
+// Partitions splits [start, end) into consecutive windows of the given granularity.
+// start is truncated to the beginning of its window in start's location; a window
+// that would extend past end is kept whole.
+func Partitions(start, end time.Time, granularity PartitionGranularity) ([]Partition, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("partitions: end %s is not after start %s", end, start)
+	}
+
+	var next func(time.Time) time.Time
+	switch granularity {
+	case PartitionHourly:
+		start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), 0, 0, 0, start.Location())
+		next = func(t time.Time) time.Time { return t.Add(time.Hour) }
+	case PartitionDaily:
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	default:
+		return nil, fmt.Errorf("partitions: unknown granularity %d", granularity)
+	}
+
+	var partitions []Partition
+	for t := start; t.Before(end); t = next(t) {
+		partitions = append(partitions, Partition{Start: t, End: next(t)})
+	}
+	return partitions, nil
+}
+
+// ExecutePartitions runs one pipeline per partition, in order. newPipeline must return a
+// freshly built pipeline each time; the partition is added as its last initial input.
+// It stops at the first failing partition and returns the results gathered so far.
+func ExecutePartitions(newPipeline func() *Pipeline, partitions []Partition) ([]*Result, error) {
+	results := make([]*Result, 0, len(partitions))
+	for _, partition := range partitions {
+		p := newPipeline()
+		p.AddInitialInputs(partition)
+		p.logger.Infof("Executing partition %s", partition)
+		result, err := p.Execute()
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("partition %s: %w", partition, err)
+		}
+	}
+	return results, nil
+}
@@ -1,16 +1,20 @@
 package pipeline
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
-
-	"github.com/sirupsen/logrus"
+	"slices"
+	"sync"
+	"time"
 )
 
-var globalLogger = logrus.New() // global logger that can be overridden by user.
+var globalLogger = newDefaultLogger() // global logger that can be overridden by user.
 
 // SetGlobalLogger allows changing the package-wide default logger.
-func SetGlobalLogger(l *logrus.Logger) {
+func SetGlobalLogger(l Logger) {
 	if l != nil {
 		globalLogger = l
 	}
@@ -23,16 +27,49 @@ type Step struct {
 }
 
 // Pipeline orchestrates steps, storing overall config and outputs.
+//
+// Run may be called from several goroutines at once: each call works on its own
+// snapshot of the steps and inputs. Methods changing the definition (adding or
+// mutating steps, inputs, observers or checks) wait for Execute and for Run calls
+// taking their snapshot. The PipelineConfig and StepConfigs must not be modified
+// while the pipeline runs.
 type Pipeline struct {
+	mu           sync.RWMutex // guards the definition below and Execute's state
 	steps        []Step
 	context      *ExecutionContext
 	config       *PipelineConfig
-	logger       *logrus.Logger
+	logger       Logger
 	stepOutputs  map[string][]interface{}
 	pickCounters map[reflect.Type]int
+	observers    []StepObserver
+	progressFns  []func(ProgressEvent)
+	startHooks   []func(context.Context, RunInfo) error
+	finishHooks  []func(context.Context, RunReport)
+
+	teardownSteps   []Step
+	assertSteps     []Step
+	preflightChecks []PreflightCheck
+	disabledSteps   map[string]bool // steps disabled with DisableStep
+	source          *Pipeline       // pipeline a run started by Run was taken from
+
+	// State of the current execution.
+	runCtx          context.Context
+	lazySteps       map[string]Step  // lazy steps not run yet
+	lazyOutcomes    map[string]error // outcome of the lazy steps run, for every later request
+	lazyRecords     []StepRecord     // records of lazy steps run on demand, not yet in the Result
+	streams         []*stream        // streaming steps started
+	openRunners     map[string]bool  // StepRunner steps run, to be closed
+	openRunnerOrder []Step
+	argsHash        string // audit hash of the arguments of the step last called
+	workers         *workerPool
+	conversions     []Conversion // converters applied to the arguments of the step last resolved
+	currentRunID    string
+	progressDone    int
+	progressTotal   int
+	partial         map[string]bool // main steps run (true) or upstream (false) in a partial run; nil for all
 }
 
-func NewPipeline(config *PipelineConfig, logger *logrus.Logger) *Pipeline {
+func NewPipeline(config *PipelineConfig, logger Logger) *Pipeline {
 	if config == nil {
 		config = NewPipelineConfig()
 	}
@@ -49,47 +86,481 @@ func NewPipeline(config *PipelineConfig, logger *logrus.Logger) *Pipeline {
 	}
 }
 
-func (p *Pipeline) SetLogger(logger *logrus.Logger) {
+func (p *Pipeline) SetLogger(logger Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if logger != nil {
 		p.logger = logger
 	}
 }
 
-func (p *Pipeline) SetLogLevel(level logrus.Level) {
-	p.logger.SetLevel(level)
+// SetLogLevel changes the minimum level of the pipeline's logger if it implements
+// LevelSetter. Other loggers, such as logrus or zap, are configured directly.
+func (p *Pipeline) SetLogLevel(level LogLevel) {
+	if setter, ok := p.logger.(LevelSetter); ok {
+		setter.SetLogLevel(level)
+		return
+	}
+	p.logger.Warnf("Logger %T does not support SetLogLevel", p.logger)
 }
 
-func (p *Pipeline) AddStep(name string, callable interface{}) {
+// AddStep appends a step to the pipeline. Options, such as WithTags, set parts of
+// its StepConfig, which is created if needed.
+func (p *Pipeline) AddStep(name string, callable interface{}, opts ...StepOption) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.steps = append(p.steps, Step{Name: name, Callable: callable})
+	p.applyStepOptions(name, opts)
 	p.logger.Debugf("Added step %q", name)
 }
 
+// AddTeardownStep registers a cleanup step that runs after the main steps, whether
+// they succeeded or not. Teardown steps run in reverse registration order and resolve
+// their arguments like any other step; their failures never mask a step failure.
+func (p *Pipeline) AddTeardownStep(name string, callable interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.teardownSteps = append(p.teardownSteps, Step{Name: name, Callable: callable})
+	p.logger.Debugf("Added teardown step %q", name)
+}
+
 func (p *Pipeline) AddInitialInputs(inputs ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.context.AddInputs(inputs...)
 	p.logger.Debugf("Added %d initial inputs", len(inputs))
 }
 
-func (p *Pipeline) Execute() (map[string][]interface{}, error) {
+// AddNamedInput adds an initial input that ArgSourceNamedInput bindings reference
+// by name, so that they keep working when inputs are reordered. Like any initial
+// input it is also available to default resolution and ArgSourceInitial.
+func (p *Pipeline) AddNamedInput(name string, value interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.context.NamedInput(name); ok {
+		p.logger.Warnf("Initial input %q added again; bindings will use the new value", name)
+	}
+	p.context.AddNamedInput(name, value)
+	p.logger.Debugf("Added initial input %q", name)
+}
+
+// Execute runs every step in order and returns a record of each execution. On failure
+// the returned Result holds the records of the steps executed so far; with
+// ErrorPolicyContinueCollect the error is a *MultiError listing every failed step.
+// Execute stores its state in the pipeline itself; use Run to execute it more than once,
+// or call Reset between executions. Concurrent calls to Execute run one after the other.
+func (p *Pipeline) Execute() (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.execute(context.Background())
+}
+
+// Run executes the pipeline with a fresh execution context seeded with the pipeline's
+// initial inputs followed by inputs. The pipeline itself is left untouched, so Run can
+// be called repeatedly, including concurrently. Steps not yet started when ctx is done
+// are not run.
+//
+// A run works on a snapshot taken when it starts: the steps, initial inputs,
+// observers, hooks and steps disabled with DisableStep, and a copy of the
+// PipelineConfig struct. The Pipeline's methods, such as AddStep with its options,
+// the step mutations of mutate.go, DisableStep, EnableStep, RegisterConverter and
+// AddInitialInputs, may be called while runs are in progress and apply from the next
+// Run. What the snapshot shares by reference is not copied:
+//   - fields of the PipelineConfig, of its registries and of its StepConfigs must
+//     not be written directly while a run is in progress;
+//   - a StepRunner value is shared by every run of its step, each calling Init, Run
+//     and Close, so it must be safe for concurrent use;
+//   - a CircuitBreaker counts the calls of every run, as it does across pipelines.
+func (p *Pipeline) Run(ctx context.Context, inputs ...interface{}) (*Result, error) {
+	return p.newRun(inputs).execute(ctx)
+}
+
+// Reset clears the state left by Execute (stored values, step outputs and pick
+// counters) so that the pipeline can be executed again. The initial inputs are kept
+// only if withInputs is true; otherwise new ones must be added before executing.
+func (p *Pipeline) Reset(withInputs bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	previous := p.context
+	p.context = NewExecutionContext()
+	if withInputs {
+		p.context.copyInputs(previous)
+	}
+	p.stepOutputs = make(map[string][]interface{})
+	p.pickCounters = make(map[reflect.Type]int)
+	p.logger.Debugf("Pipeline reset")
+}
+
+// newRun returns a pipeline with a snapshot of p's definition and its own execution state.
+func (p *Pipeline) newRun(inputs []interface{}) *Pipeline {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	// Step options and RegisterConverter replace fields of p.config; the run keeps
+	// those it started with.
+	config := *p.config
+	run := &Pipeline{
+		steps:        slices.Clone(p.steps),
+		context:      NewExecutionContext(),
+		config:       &config,
+		logger:       p.logger,
+		stepOutputs:  make(map[string][]interface{}),
+		pickCounters: make(map[reflect.Type]int),
+		observers:    slices.Clone(p.observers),
+		progressFns:  slices.Clone(p.progressFns),
+		startHooks:   slices.Clone(p.startHooks),
+		finishHooks:  slices.Clone(p.finishHooks),
+
+		teardownSteps:   slices.Clone(p.teardownSteps),
+		assertSteps:     slices.Clone(p.assertSteps),
+		preflightChecks: slices.Clone(p.preflightChecks),
+		disabledSteps:   maps.Clone(p.disabledSteps),
+		source:          p,
+	}
+	run.context.copyInputs(p.context)
+	run.context.AddInputs(inputs...)
+	return run
+}
+
+func (p *Pipeline) execute(ctx context.Context) (result *Result, err error) {
+	result = &Result{RunID: runID(ctx)}
+	p.beginRun(ctx, result.RunID)
+	p.currentRunID, p.progressDone, p.progressTotal = result.RunID, 0, 0
+	var started *RunInfo // set once the OnStart hooks are called
+	defer func() {
+		p.progress(ProgressRunFinished, "", result.Duration(), err)
+		if p.config.Audit {
+			result.Audit = p.auditChain(result)
+		}
+		if started != nil {
+			p.callFinishHooks(ctx, started, result, err)
+		}
+		p.finishRun(result, err)
+		p.notify(ctx, result, err)
+		if err == nil {
+			p.trigger(ctx, result)
+		}
+	}()
+
+	// The run waits for its group and runs its steps within the deadline; notifiers
+	// and triggered runs do not.
+	stepsCtx := ctx
+	if deadline, ok := p.runDeadline(); ok {
+		var cancel context.CancelFunc
+		stepsCtx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	unlock, err := p.acquireConcurrencyGroup(stepsCtx)
+	if err != nil {
+		err = p.deadlineError(ctx, stepsCtx, result, err)
+		p.logger.Errorf("Pipeline not started: %v", err)
+		return result, err
+	}
+	defer unlock()
+
+	if err := p.context.inputError(); err != nil {
+		p.logger.Errorf("Pipeline not started: %v", err)
+		return result, err
+	}
+	if err := p.checkCycles(); err != nil {
+		p.logger.Errorf("Pipeline not started: %v", err)
+		return result, err
+	}
+	if err := p.preflight(stepsCtx); err != nil {
+		p.logger.Errorf("Pipeline not started: %v", err)
+		return result, err
+	}
+	started = p.newRunInfo(ctx, result.RunID)
+	if err := p.callStartHooks(stepsCtx, started); err != nil {
+		p.logger.Errorf("Pipeline not started: %v", err)
+		return result, err
+	}
+
+	p.runCtx, p.workers = stepsCtx, newWorkerPool(p.config.Workers)
+	defer func() { p.runCtx, p.workers = nil, nil }()
+
 	// 1) Possibly reorder steps based on config.StepOrder
 	p.reorderStepsIfNeeded()
 
-	// 2) Execute steps
-	for _, step := range p.steps {
-		p.logger.Infof("Executing step %q", step.Name)
+	// 2) Execute steps, assertions if they succeeded, then teardown steps whatever the outcome
+	err = p.runSteps(stepsCtx, result)
+	if streamErr := p.waitStreams(result); streamErr != nil && err == nil {
+		err = streamErr
+	}
+	err = p.deadlineError(ctx, stepsCtx, result, err)
+	var assertErr error
+	if err == nil {
+		assertErr = p.runAssertions(result)
+	}
+	if teardownErr := p.runTeardown(result); teardownErr != nil && err == nil {
+		err = teardownErr
+	}
+	if closeErr := p.closeRunners(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	p.skipUnusedLazySteps(result)
+
+	// 3) Filter outputs if specified
+	result.outputs = p.filterOutputs()
+	if err != nil {
+		return result, err
+	}
+	// A partial run leaves the checkpoints for the next one.
+	if p.config.CheckpointStore != nil && p.partial == nil {
+		if err := p.config.CheckpointStore.Clear(p.checkpointKey()); err != nil {
+			p.logger.Warnf("Could not clear checkpoints: %v", err)
+		}
+	}
+	if assertErr != nil {
+		p.logger.Warnf("Pipeline execution complete with quality failures: %v", assertErr)
+		return result, assertErr
+	}
+	p.logger.Infof("Pipeline execution complete")
+	return result, nil
+}
+
+// runSteps executes the main steps in order, appending a record for each to result.
+func (p *Pipeline) runSteps(ctx context.Context, result *Result) error {
+	checkpoints, err := p.loadCheckpoints()
+	if err != nil {
+		p.logger.Errorf("Pipeline not started: %v", err)
+		return err
+	}
+
+	p.lazySteps, p.lazyOutcomes = make(map[string]Step), make(map[string]error)
+	var failures []error
+	// finish records a step that ran and returns its failure if the run must stop.
+	finish := func(step Step, record StepRecord) error {
+		if record.Err == nil {
+			record.Err = p.saveCheckpoint(step, record.Outputs)
+		}
+		p.flushLazyRecords(result)
+		record.Err = p.stepError(step, len(result.Steps), record.Err)
+		result.Steps = append(result.Steps, record)
+		if record.Err == nil {
+			return nil
+		}
+		switch p.severity(step) {
+		case SeverityBestEffort:
+			p.logger.Warnf("Best-effort step %q failed: %v", step.Name, record.Err)
+		case SeverityCritical:
+			p.logger.Errorf("Critical step %q failed: %v", step.Name, record.Err)
+			return record.Err
+		default:
+			p.logger.Errorf("Step %q failed: %v", step.Name, record.Err)
+			if p.config.ErrorPolicy != ErrorPolicyContinueCollect {
+				return record.Err
+			}
+			failures = append(failures, record.Err)
+		}
+		return nil
+	}
+
+	steps, _ := p.plannedSteps()
+	p.progressTotal = len(steps) + len(p.assertSteps) + len(p.teardownSteps)
+	var batches []int
+	if p.config.AutoParallel {
+		batches = p.parallelBatches(steps, checkpoints)
+	}
+	for i := 0; i < len(steps); i++ {
+		step := steps[i]
+		if err := ctx.Err(); err != nil {
+			p.logger.Errorf("Pipeline stopped before step %q: %v", step.Name, err)
+			return err
+		}
+		cp, checkpointed := checkpoints[step.Name]
+		if checkpointed && p.upstream(step.Name) {
+			// Upstream of a partial run: its outputs come from the checkpoint.
+			if err := p.resumeStep(step, cp, result); err != nil {
+				return err
+			}
+			continue
+		}
+		if record, disabled := p.disabledRecord(step); disabled {
+			result.Steps = append(result.Steps, record)
+			continue
+		}
+		if p.isLazy(step) {
+			p.logger.Debugf("Deferring lazy step %q until its outputs are requested", step.Name)
+			p.lazySteps[step.Name] = step
+			continue
+		}
+		if checkpointed {
+			if err := p.resumeStep(step, cp, result); err != nil {
+				return err
+			}
+			continue
+		}
 
-		// Reset pickCounters for each step
-		p.pickCounters = make(map[reflect.Type]int)
+		if !p.shouldRun(step) {
+			p.logger.Infof("Skipping step %q: condition not met", step.Name)
+			now := time.Now()
+			result.Steps = append(result.Steps, StepRecord{Name: step.Name, Start: now, End: now, Skipped: true})
+			p.progress(ProgressStepSkipped, step.Name, 0, nil)
+			continue
+		}
 
-		if err := p.executeStep(step); err != nil {
-			p.logger.Errorf("Step %q failed: %v", step.Name, err)
-			return nil, err
+		if i < len(batches) && batches[i] > 1 {
+			batch := steps[i : i+batches[i]]
+			i += len(batch) - 1
+			// Every step of the batch ran: all are recorded before stopping.
+			var stop error
+			for j, record := range p.runParallel(batch) {
+				if err := finish(batch[j], record); err != nil && stop == nil {
+					stop = err
+				}
+			}
+			if stop != nil {
+				return stop
+			}
+			continue
+		}
+		if err := finish(step, p.runStep(step)); err != nil {
+			return err
 		}
 	}
 
-	// 3) Filter outputs if specified
-	finalOutputs := p.filterOutputs()
-	p.logger.Info("Pipeline execution complete")
-	return finalOutputs, nil
+	if len(failures) > 0 {
+		p.logger.Errorf("Pipeline execution complete with %d failed step(s)", len(failures))
+		return &MultiError{Errors: failures}
+	}
+	return nil
+}
+
+// runTeardown executes the teardown steps in reverse registration order. Every
+// teardown step runs even if an earlier one fails; their failures are returned together.
+func (p *Pipeline) runTeardown(result *Result) error {
+	var failures []error
+	for i := len(p.teardownSteps) - 1; i >= 0; i-- {
+		step := p.teardownSteps[i]
+		if record, disabled := p.disabledRecord(step); disabled {
+			record.Teardown = true
+			result.Steps = append(result.Steps, record)
+			continue
+		}
+		record := p.runStep(step)
+		record.Teardown = true
+		p.flushLazyRecords(result)
+		record.Err = p.stepError(step, len(result.Steps), record.Err)
+		result.Steps = append(result.Steps, record)
+		if record.Err != nil {
+			p.logger.Errorf("Teardown step %q failed: %v", step.Name, record.Err)
+			failures = append(failures, record.Err)
+		}
+	}
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return failures[0]
+	default:
+		return &MultiError{Errors: failures}
+	}
+}
+
+// resumeStep restores the outputs of step from its checkpoint, recording it as resumed.
+func (p *Pipeline) resumeStep(step Step, cp Checkpoint, result *Result) error {
+	p.logger.Infof("Restoring step %q from checkpoint", step.Name)
+	now := time.Now()
+	record := StepRecord{Name: step.Name, Start: now, End: now, Resumed: true}
+	p.progress(ProgressStepSkipped, step.Name, 0, nil)
+	record.Outputs, record.Err = p.restoreCheckpoint(step, cp)
+	record.Err = p.stepError(step, len(result.Steps), record.Err)
+	result.Steps = append(result.Steps, record)
+	if record.Err != nil {
+		p.logger.Errorf("Step %q failed: %v", step.Name, record.Err)
+	}
+	return record.Err
+}
+
+// stepError wraps a non-nil failure of step in a *StepError.
+func (p *Pipeline) stepError(step Step, index int, err error) error {
+	if err == nil {
+		return nil
+	}
+	code := ErrorCodeOf(err)
+	if code == CodeUnknown {
+		code = CodeStepFailed
+	}
+	return &StepError{Pipeline: p.config.Name, Step: step.Name, Index: index, Code: code, Cause: err}
+}
+
+// runStep executes a single step, timing it and notifying observers.
+func (p *Pipeline) runStep(step Step) StepRecord {
+	base := p.logger
+	p.logger = p.stepLogger(step)
+	defer func() { p.logger = base }()
+	p.logger.Infof("Executing step %q", step.Name)
+
+	// Reset pickCounters for each step
+	p.pickCounters = make(map[reflect.Type]int)
+
+	p.notifyStepStarted(step)
+	record := StepRecord{Name: step.Name, Start: time.Now()}
+	p.argsHash, p.conversions = "", nil
+	hb := p.startHeartbeat(step)
+	if record.Waited, record.Err = p.waitFor(step, hb); record.Err == nil {
+		record.Outputs, record.Cached, record.Err = p.executeStep(step)
+	}
+	hb.stop()
+	record.inputsHash, record.Conversions = p.argsHash, p.conversions
+	record.End = time.Now()
+	record.Duration = record.End.Sub(record.Start)
+	if record.Err != nil || !isStreamingStep(reflect.TypeOf(step.Callable)) {
+		// Streaming steps are reported by waitStreams once they return.
+		p.notifyStepFinished(step, record.Duration, record.Err)
+	}
+	return record
+}
+
+var loggerType = reflect.TypeOf((*Logger)(nil)).Elem()
+
+// stepLogger returns the logger used while step runs: its StepConfig.Logger or the
+// pipeline's, with the pipeline name (if any), run ID and step name attached. Steps with a Logger
+// parameter receive it too.
+func (p *Pipeline) stepLogger(step Step) Logger {
+	logger := p.logger
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok && stepCfg.Logger != nil {
+		logger = stepCfg.Logger
+	}
+	var fields []interface{}
+	if p.config.Name != "" {
+		fields = append(fields, "pipeline", p.config.Name)
+	}
+	if p.currentRunID != "" {
+		fields = append(fields, "run_id", p.currentRunID)
+	}
+	return withFields(logger, append(fields, "step", step.Name)...)
+}
+
+func (p *Pipeline) severity(step Step) Severity {
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok {
+		return stepCfg.Severity
+	}
+	return SeverityDefault
+}
+
+// disabledRecord returns the record of a skipped step if step is disabled.
+func (p *Pipeline) disabledRecord(step Step) (StepRecord, bool) {
+	if !p.isDisabled(step.Name) {
+		return StepRecord{}, false
+	}
+	if p.deselected(step.Name) {
+		p.logger.Infof("Skipping step %q: not selected", step.Name)
+	} else {
+		p.logger.Infof("Skipping step %q: disabled", step.Name)
+	}
+	now := time.Now()
+	p.progress(ProgressStepSkipped, step.Name, 0, nil)
+	return StepRecord{Name: step.Name, Start: now, End: now, Skipped: true, Disabled: true}, true
+}
+
+// shouldRun evaluates the step's Condition, if any.
+func (p *Pipeline) shouldRun(step Step) bool {
+	stepCfg, ok := p.config.StepConfigs[step.Name]
+	if !ok || stepCfg.Condition == nil {
+		return true
+	}
+	return stepCfg.Condition(p.context)
 }
 
 // reorderStepsIfNeeded reorders p.steps according to config.StepOrder (if any).
@@ -98,6 +569,17 @@ func (p *Pipeline) reorderStepsIfNeeded() {
 		// No step order specified, do nothing
 		return
 	}
+	for _, name := range p.unknownOrderedSteps() {
+		p.logger.Warnf("Step name %q in StepOrder does not exist in pipeline steps", name)
+	}
+	p.steps = p.orderedSteps()
+}
+
+// orderedSteps returns p.steps arranged according to config.StepOrder, without modifying p.steps.
+func (p *Pipeline) orderedSteps() []Step {
+	if len(p.config.StepOrder) == 0 {
+		return p.steps
+	}
 
 	// Step 1: build a map from stepName => pointer to Step (for quick lookup)
 	stepMap := make(map[string]*Step)
@@ -112,7 +594,6 @@ func (p *Pipeline) reorderStepsIfNeeded() {
 	for _, desiredName := range p.config.StepOrder {
 		st, exists := stepMap[desiredName]
 		if !exists {
-			p.logger.Warnf("Step name %q in StepOrder does not exist in pipeline steps", desiredName)
 			continue
 		}
 		ordered = append(ordered, *st)
@@ -125,30 +606,75 @@ func (p *Pipeline) reorderStepsIfNeeded() {
 			ordered = append(ordered, s)
 		}
 	}
+	return ordered
+}
 
-	// Step 4: overwrite p.steps with the new ordering
-	p.steps = ordered
+// unknownOrderedSteps returns the names in config.StepOrder that match no step.
+func (p *Pipeline) unknownOrderedSteps() []string {
+	known := make(map[string]bool, len(p.steps))
+	for _, s := range p.steps {
+		known[s.Name] = true
+	}
+	var unknown []string
+	for _, name := range p.config.StepOrder {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
 }
 
-func (p *Pipeline) executeStep(step Step) error {
+// executeStep resolves the step's arguments, calls it (unless its outputs are cached)
+// and stores its outputs.
+func (p *Pipeline) executeStep(step Step) (outputs []interface{}, cached bool, err error) {
+	if runner, ok := step.Callable.(StepRunner); ok {
+		outputs, err = p.executeRunner(step, runner)
+		return outputs, false, err
+	}
 	fnValue := reflect.ValueOf(step.Callable)
-	fnType := fnValue.Type()
-	numIn := fnType.NumIn()
-	args := make([]reflect.Value, numIn)
+	args := make([]reflect.Value, fnValue.Type().NumIn())
+	if err := p.resolveArgs(step, fnValue.Type(), args); err != nil {
+		return nil, false, err
+	}
 
+	if isStreamingStep(fnValue.Type()) {
+		return p.startStream(step, fnValue, args), false, nil
+	}
+
+	call := p.prepareCall(step, fnValue, args)
+	p.argsHash = call.argsHash
+	p.invoke(call)
+	outputs, err = p.storeCall(call)
+	return outputs, call.cached, err
+}
+
+// resolveArgs resolves every parameter of the step into args, leaving the send
+// channels of streaming steps to startStream.
+func (p *Pipeline) resolveArgs(step Step, fnType reflect.Type, args []reflect.Value) error {
 	stepCfg, hasStepCfg := p.config.StepConfigs[step.Name]
 	var bindings []*ArgBinding
 	if hasStepCfg {
 		bindings = stepCfg.ArgBindings
 	}
+	streaming := isStreamingStep(fnType)
 
-	for i := 0; i < numIn; i++ {
+	for i := 0; i < fnType.NumIn(); i++ {
 		var argVal reflect.Value
 		var err error
 
 		// If we have a custom ArgBinding, use it; else default
 		if hasStepCfg && i < len(bindings) && bindings[i] != nil {
 			argVal, err = p.resolveArg(step, fnType.In(i), bindings[i])
+		} else if fnType.In(i) == parallelismType {
+			argVal = reflect.ValueOf(p.stepParallelism(step))
+		} else if fnType.In(i) == loggerType {
+			// A copy: p.logger changes as other steps run, in parallel or streaming.
+			logger := p.logger
+			argVal = reflect.ValueOf(&logger).Elem()
+		} else if fnType.In(i) == workerPoolType {
+			argVal = reflect.ValueOf(p.workers)
+		} else if streaming && isSendChan(fnType.In(i)) {
+			continue // created by startStream
 		} else {
 			argVal, err = p.resolveArgDefault(step, fnType.In(i))
 		}
@@ -157,27 +683,134 @@ func (p *Pipeline) executeStep(step Step) error {
 			return err
 		}
 		args[i] = argVal
+		for j := range p.conversions {
+			if p.conversions[j].Param < 0 {
+				p.conversions[j].Param = i
+			}
+		}
 	}
+	return nil
+}
 
-	results := fnValue.Call(args)
-	p.context.StoreResults(results)
+// stepCall is a call of a step whose arguments are resolved.
+type stepCall struct {
+	step     Step
+	fnValue  reflect.Value
+	args     []reflect.Value
+	argsHash string // see PipelineConfig.Audit
+	cacheKey string
+	results  []reflect.Value
+	cached   bool
+	err      error
+}
+
+// prepareCall hashes the arguments of the call and looks its outputs up in the cache.
+func (p *Pipeline) prepareCall(step Step, fnValue reflect.Value, args []reflect.Value) *stepCall {
+	call := &stepCall{step: step, fnValue: fnValue, args: args}
+	if p.config.Audit {
+		call.argsHash = p.auditHash(args)
+	}
+	call.cacheKey = p.memoizeKey(step, args)
+	if call.cacheKey != "" {
+		if outs, ok := p.config.Cache.Get(step.Name, call.cacheKey); ok {
+			call.results, call.cached = outputValues(fnValue.Type(), outs)
+		}
+	}
+	return call
+}
+
+// invoke calls the step unless its outputs were cached. It only touches the call
+// and the step's CPU budget and circuit breaker, so calls may be invoked concurrently.
+func (p *Pipeline) invoke(call *stepCall) {
+	if call.cached {
+		return
+	}
+	unlock, err := p.acquireSerialGroup(call.step)
+	if err != nil {
+		call.err = err
+		return
+	}
+	defer unlock()
+	release := p.reserveCPUs(call.step)
+	call.results, call.err = p.callStep(call.step, call.fnValue, call.args)
+	release()
+}
+
+// storeCall stores the outputs of a successful call in the context and the cache.
+func (p *Pipeline) storeCall(call *stepCall) ([]interface{}, error) {
+	if call.err != nil {
+		return nil, call.err
+	}
+	if call.cached {
+		p.logger.Debugf("Step %q outputs taken from cache", call.step.Name)
+	}
+	p.context.StoreResults(call.results)
 
 	var resultInterfaces []interface{}
-	for _, r := range results {
+	for _, r := range call.results {
 		resultInterfaces = append(resultInterfaces, r.Interface())
 	}
-	p.stepOutputs[step.Name] = append(p.stepOutputs[step.Name], resultInterfaces...)
+	p.stepOutputs[call.step.Name] = append(p.stepOutputs[call.step.Name], resultInterfaces...)
 
-	p.logger.Debugf("Step %q produced %d outputs", step.Name, len(results))
-	return nil
+	if call.cacheKey != "" && !call.cached {
+		p.config.Cache.Set(call.step.Name, call.cacheKey, resultInterfaces, p.config.StepConfigs[call.step.Name].CacheTTL)
+	}
+
+	p.logger.Debugf("Step %q produced %d outputs", call.step.Name, len(call.results))
+	return resultInterfaces, nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// returnedError returns the non-nil error a step reported through its last return value, if any.
+func returnedError(fnType reflect.Type, results []reflect.Value) error {
+	n := fnType.NumOut()
+	if n == 0 || fnType.Out(n-1) != errorType || results[n-1].IsNil() {
+		return nil
+	}
+	return results[n-1].Interface().(error)
 }
 
+// resolveArg resolves a parameter with an explicit binding, falling back to the
+// binding's Default if there is no value to bind, such as the output of a
+// disabled or skipped step. Other failures, like a value of the wrong type or a
+// lazy step that failed, are returned.
 func (p *Pipeline) resolveArg(step Step, paramType reflect.Type, binding *ArgBinding) (reflect.Value, error) {
+	val, err := p.resolveBinding(step, paramType, binding)
+	var lazyErr *lazyStepError
+	if binding.Default != nil && ErrorCodeOf(err) == CodeArgNotFound && !errors.As(err, &lazyErr) {
+		p.logger.Debugf("Step %q: using default for %s: %v", step.Name, paramType, err)
+		return p.resolveArgFromLiteral(step, paramType, binding.Default)
+	}
+	return val, err
+}
+
+func (p *Pipeline) resolveBinding(step Step, paramType reflect.Type, binding *ArgBinding) (reflect.Value, error) {
 	switch binding.Source {
 	case ArgSourceInitial:
 		return p.resolveArgFromInitial(step, paramType, binding.Index)
 	case ArgSourceFunctionOutput:
-		return p.resolveArgFromFunctionOutput(step, paramType, binding.Name, binding.Index)
+		index := binding.Index
+		if binding.OutputName != "" {
+			var err error
+			index, err = p.outputIndexByName(step, binding.Name, binding.OutputName)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		return p.resolveArgFromFunctionOutput(step, paramType, binding.Name, index)
+	case ArgSourceLiteral:
+		return p.resolveArgFromLiteral(step, paramType, binding.Value)
+	case ArgSourceEnv:
+		return p.resolveArgFromEnv(step, paramType, binding.Name, binding.Value)
+	case ArgSourceCollect:
+		return p.resolveArgCollect(step, paramType, binding.Names)
+	case ArgSourceNamedInput:
+		index, ok := p.context.NamedInput(binding.Name)
+		if !ok {
+			return reflect.Value{}, withCode(CodeArgNotFound, fmt.Errorf("no initial input named %q", binding.Name))
+		}
+		return p.resolveArgFromInitial(step, paramType, index)
 	case ArgSourceDefault:
 		return p.resolveArgDefault(step, paramType)
 	default:
@@ -187,71 +820,172 @@ func (p *Pipeline) resolveArg(step Step, paramType reflect.Type, binding *ArgBin
 
 func (p *Pipeline) resolveArgDefault(step Step, paramType reflect.Type) (reflect.Value, error) {
 	switch p.config.MissingArgPolicy {
-	case MissingArgPolicyUseLatest:
-		idx := p.pickCounters[paramType]
-		val, err := p.context.getValueByIndex(paramType, idx)
+	case MissingArgPolicyUseLatest, MissingArgPolicyZeroValue:
+		if canCollect(paramType, p.context.values) {
+			// Fan-in: a []T parameter receives every T produced so far.
+			return p.context.collect(paramType), nil
+		}
+		srcType := paramType
+		if p.config.AllowConversion && len(p.context.candidates(paramType)) == 0 {
+			t, err := convertibleSource(paramType, p.context.values)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if t != nil {
+				srcType = t
+			}
+		}
+		idx := p.pickCounters[srcType]
+		val, err := p.context.getValueByIndex(srcType, idx)
+		if err != nil && p.config.MissingArgPolicy == MissingArgPolicyZeroValue {
+			p.logger.Debugf("Step %q: no value of type %s, using its zero value", step.Name, paramType)
+			return reflect.Zero(paramType), nil
+		}
 		if err != nil {
-			return reflect.Value{}, fmt.Errorf("step %s: cannot find value for type %s: %w",
-				step.Name, paramType, err)
+			return reflect.Value{}, withCode(CodeArgNotFound, fmt.Errorf("cannot find value for type %s: %w", paramType, err))
 		}
-		vals := p.context.values[paramType]
+		vals := p.context.candidates(srcType)
 		if idx < len(vals)-1 {
-			p.pickCounters[paramType] = idx + 1
+			p.pickCounters[srcType] = idx + 1
 		}
-		return val, nil
+		return bindValue(val, paramType), nil
 
 	case MissingArgPolicyFail:
-		return reflect.Value{}, fmt.Errorf("step %s: missing argument for type %s (policy=fail)",
-			step.Name, paramType)
+		return reflect.Value{}, withCode(CodeArgNotFound, fmt.Errorf("missing argument for type %s (policy=fail)", paramType))
 
 	default:
-		return reflect.Value{}, fmt.Errorf("step %s: unknown MissingArgPolicy", step.Name)
+		return reflect.Value{}, errors.New("unknown MissingArgPolicy")
+	}
+}
+
+// canCollect reports whether a missing slice-typed parameter can be filled with values of its element type.
+func canCollect[V any](paramType reflect.Type, values map[reflect.Type][]V) bool {
+	return paramType.Kind() == reflect.Slice && len(values[paramType]) == 0 && len(values[paramType.Elem()]) > 0
+}
+
+func (p *Pipeline) resolveArgCollect(step Step, paramType reflect.Type, stepNames []string) (reflect.Value, error) {
+	if paramType.Kind() != reflect.Slice {
+		return reflect.Value{}, withCode(CodeTypeMismatch, fmt.Errorf("ArgSourceCollect requires a slice parameter, got %s", paramType))
+	}
+	if len(stepNames) == 0 {
+		return p.context.collect(paramType), nil
+	}
+	for _, name := range stepNames {
+		if err := p.runLazyStep(name); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	elemType := paramType.Elem()
+	collected := reflect.MakeSlice(paramType, 0, 0)
+	for _, name := range stepNames {
+		for _, out := range p.stepOutputs[name] {
+			val := reflect.ValueOf(out)
+			if val.IsValid() && p.assignable(val.Type(), elemType) {
+				collected = reflect.Append(collected, bindValue(val, elemType))
+			}
+		}
 	}
+	return collected, nil
 }
 
 func (p *Pipeline) resolveArgFromInitial(step Step, paramType reflect.Type, index int) (reflect.Value, error) {
 	allInitial := p.context.InitialValues()
 	if index < 0 || index >= len(allInitial) {
-		return reflect.Value{}, fmt.Errorf("step %s: ArgSourceInitial index %d out of range (%d total)",
-			step.Name, index, len(allInitial))
+		return reflect.Value{}, withCode(CodeArgNotFound, fmt.Errorf("ArgSourceInitial index %d out of range (%d total)",
+			index, len(allInitial)))
 	}
 	val := allInitial[index]
-	if !val.Type().AssignableTo(paramType) {
-		return reflect.Value{}, fmt.Errorf("step %s: initial input %d has type %s, not assignable to %s",
-			step.Name, index, val.Type(), paramType)
+	if !p.assignable(val.Type(), paramType) {
+		if converted, ok, err := p.convertBound(val, paramType, valueRef{Index: index}); ok {
+			return converted, err
+		}
+		return reflect.Value{}, withCode(CodeTypeMismatch, fmt.Errorf("initial input %d has type %s, not assignable to %s",
+			index, val.Type(), paramType))
 	}
-	return val, nil
+	return bindValue(val, paramType), nil
 }
 
 func (p *Pipeline) resolveArgFromFunctionOutput(step Step, paramType reflect.Type, funcName string, outputIndex int) (reflect.Value, error) {
+	if err := p.runLazyStep(funcName); err != nil {
+		return reflect.Value{}, err
+	}
 	outputs, ok := p.stepOutputs[funcName]
 	if !ok {
-		return reflect.Value{}, fmt.Errorf("step %s: function %s has no recorded outputs", step.Name, funcName)
+		return reflect.Value{}, withCode(CodeArgNotFound, fmt.Errorf("function %s has no recorded outputs", funcName))
 	}
 	if outputIndex < 0 || outputIndex >= len(outputs) {
-		return reflect.Value{}, fmt.Errorf("step %s: requested output index %d of function %s but it has %d outputs",
-			step.Name, outputIndex, funcName, len(outputs))
+		return reflect.Value{}, withCode(CodeValidationFailed, fmt.Errorf("requested output index %d of function %s but it has %d outputs",
+			outputIndex, funcName, len(outputs)))
 	}
 	out := outputs[outputIndex]
+	if out == nil {
+		// A nil pointer, map, slice or interface returned by the step.
+		if !nilable(paramType) {
+			return reflect.Value{}, withCode(CodeTypeMismatch, fmt.Errorf("output %d of function %s is nil, not assignable to %s",
+				outputIndex, funcName, paramType))
+		}
+		return reflect.Zero(paramType), nil
+	}
 	val := reflect.ValueOf(out)
-	if !val.Type().AssignableTo(paramType) {
-		return reflect.Value{}, fmt.Errorf("step %s: output type %s from function %s not assignable to %s",
-			step.Name, val.Type(), funcName, paramType)
+	if !p.assignable(val.Type(), paramType) {
+		if converted, ok, err := p.convertBound(val, paramType, valueRef{Step: funcName, Index: outputIndex}); ok {
+			return converted, err
+		}
+		return reflect.Value{}, withCode(CodeTypeMismatch, fmt.Errorf("output type %s from function %s not assignable to %s",
+			val.Type(), funcName, paramType))
+	}
+	return bindValue(val, paramType), nil
+}
+
+func (p *Pipeline) resolveArgFromLiteral(step Step, paramType reflect.Type, value interface{}) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Value{}, withCode(CodeArgNotFound, fmt.Errorf("ArgSourceLiteral has no value for type %s", paramType))
+	}
+	val := reflect.ValueOf(value)
+	if !p.assignable(val.Type(), paramType) {
+		return reflect.Value{}, withCode(CodeTypeMismatch, fmt.Errorf("literal of type %s not assignable to %s", val.Type(), paramType))
 	}
-	return val, nil
+	return bindValue(val, paramType), nil
+}
+
+// outputIndexByName maps an output name declared in funcName's StepConfig to its position.
+func (p *Pipeline) outputIndexByName(step Step, funcName, outputName string) (int, error) {
+	index := p.outputNameIndex(funcName, outputName)
+	if index < 0 {
+		return 0, withCode(CodeValidationFailed, fmt.Errorf("function %s has no output named %q", funcName, outputName))
+	}
+	return index, nil
+}
+
+// outputNameIndex returns the position of outputName in funcName's OutputNames, or -1.
+func (p *Pipeline) outputNameIndex(funcName, outputName string) int {
+	if producerCfg, ok := p.config.StepConfigs[funcName]; ok {
+		for i, name := range producerCfg.OutputNames {
+			if name == outputName {
+				return i
+			}
+		}
+	}
+	return -1
 }
 
 func (p *Pipeline) filterOutputs() map[string][]interface{} {
 	if len(p.config.OutputFilter) == 0 {
 		return p.stepOutputs
 	}
-	selected := make(map[string][]interface{})
-	filterSet := make(map[string]struct{})
-	for _, name := range p.config.OutputFilter {
-		filterSet[name] = struct{}{}
+	filter, _ := compileOutputFilter(p.config.OutputFilter)
+	for _, entry := range p.config.OutputFilter {
+		if _, ok := p.stepOutputs[entry]; ok {
+			continue
+		}
+		if _, errs := compileOutputFilter([]string{entry}); len(errs) > 0 {
+			p.logger.Warnf("Ignoring %v", errs[0])
+		}
 	}
+	selected := make(map[string][]interface{})
 	for stepName, outputs := range p.stepOutputs {
-		if _, wanted := filterSet[stepName]; wanted {
+		if filter.match(stepName) {
 			selected[stepName] = outputs
 		}
 	}
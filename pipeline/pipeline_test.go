@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBindingDefaultOnlyReplacesMissingValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		binding  ArgBinding
+		disable  bool
+		wantCode ErrorCode
+	}{
+		{name: "disabled producer", binding: ArgBinding{Source: ArgSourceFunctionOutput, Name: "produce"}, disable: true},
+		{name: "unset variable", binding: ArgBinding{Source: ArgSourceEnv, Name: "PIPELINE_TEST_UNSET"}},
+		{name: "wrong type", binding: ArgBinding{Source: ArgSourceFunctionOutput, Name: "produce"}, wantCode: CodeTypeMismatch},
+		{name: "unknown output name", binding: ArgBinding{Source: ArgSourceFunctionOutput, Name: "produce", OutputName: "nope"}, wantCode: CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := tt.binding
+			binding.Default = 7
+			cfg := NewPipelineConfig()
+			cfg.StepConfigs = map[string]*StepConfig{"consume": {ArgBindings: []*ArgBinding{&binding}}}
+			p := NewPipeline(cfg, discardLogger)
+			p.AddStep("produce", func() string { return "x" })
+			var got int
+			p.AddStep("consume", func(n int) { got = n })
+			if tt.disable {
+				if err := p.DisableStep("produce"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := p.Run(context.Background())
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Run: %v", err)
+				}
+				if got != 7 {
+					t.Errorf("consume got %d, want the default 7", got)
+				}
+				return
+			}
+			if code := ErrorCodeOf(err); code != tt.wantCode {
+				t.Fatalf("Run = %v (code %s), want code %s", err, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestBindingDefaultAfterFailedLazyStep(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.StepConfigs = map[string]*StepConfig{
+		"produce": {Lazy: true, ArgBindings: []*ArgBinding{{Source: ArgSourceNamedInput, Name: "missing"}}},
+		"consume": {ArgBindings: []*ArgBinding{{Source: ArgSourceFunctionOutput, Name: "produce", Default: 7}}},
+	}
+	p := NewPipeline(cfg, discardLogger)
+	p.AddStep("produce", func(s string) int { return len(s) })
+	p.AddStep("consume", func(n int) {})
+
+	if _, err := p.Run(context.Background()); err == nil {
+		t.Fatal("Run succeeded with the default, want the lazy step's failure")
+	}
+}
+
+func TestConcurrentRunsWithMutations(t *testing.T) {
+	p := NewPipeline(nil, discardLogger)
+	p.AddStep("produce", func() int { return 42 })
+	p.AddStep("toggled", func(n int) int { return n })
+	// Keeps the runs going while the definition changes, without synchronizing with it.
+	p.AddStep("pause", func() { time.Sleep(time.Millisecond) })
+	p.AddStep("consume", func(n int) int { return n + 1 })
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		types := []reflect.Type{reflect.TypeOf(int8(0)), reflect.TypeOf(int16(0)), reflect.TypeOf(int32(0)),
+			reflect.TypeOf(int64(0)), reflect.TypeOf(uint(0)), reflect.TypeOf(uint8(0))}
+		for i := 0; i < 100; i++ {
+			p.AddStep(fmt.Sprintf("added-%d", i), func() {}, WithTags("added"))
+			if err := p.DisableStep("toggled"); err != nil {
+				t.Error(err)
+			}
+			if i < len(types) {
+				fn := reflect.MakeFunc(reflect.FuncOf([]reflect.Type{types[i]}, []reflect.Type{reflect.TypeOf("")}, false),
+					func([]reflect.Value) []reflect.Value { return []reflect.Value{reflect.ValueOf("")} })
+				if err := p.RegisterConverter(fn.Interface()); err != nil {
+					t.Error(err)
+				}
+			}
+			if err := p.EnableStep("toggled"); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				res, err := p.Run(context.Background())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := res.Steps[3].Outputs; len(got) != 1 || got[0] != 43 {
+					t.Errorf("consume outputs = %v, want [43]", got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkRun measures a run of four steps with three arguments each, the hot
+// path of a pipeline run many times over.
+func BenchmarkRun(b *testing.B) {
+	p := NewPipeline(nil, discardLogger)
+	p.AddStep("parse", func(s string, n int, f float64) (int, float64) { return n + len(s), f * 2 })
+	p.AddStep("scale", func(n int, f float64, s string) string { return s })
+	p.AddStep("sum", func(n int, f float64, s string) float64 { return f + float64(n) })
+	p.AddStep("format", func(s string, n int, f float64) bool { return n > 0 })
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Run(ctx, "input", 3, 1.5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
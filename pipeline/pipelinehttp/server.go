@@ -0,0 +1,356 @@
+package pipelinehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"pipeline/pipeline"
+)
+
+// DefaultMaxRuns is the number of finished runs a Server keeps by default.
+const DefaultMaxRuns = 1000
+
+// Server is an http.Handler exposing registered pipelines as a small job service:
+//
+//	GET  /pipelines                list the registered pipelines
+//	POST /pipelines/{name}/runs    start a run, with body {"inputs": [...]}; answers 202 with its run ID
+//	GET  /runs/{id}                poll the status of a run
+//	GET  /runs/{id}/outputs        fetch the outputs of a finished run
+//	POST /runs/cancel              cancel the runs in progress matching {"pipeline": ..., "labels": {...}}
+//
+// Runs execute in the background with Pipeline.Run. Errors are answered as
+// {"error": "..."} with an appropriate status code.
+type Server struct {
+	// MaxRuns bounds the finished runs kept for polling, oldest evicted first;
+	// DefaultMaxRuns if zero.
+	MaxRuns int
+
+	mux *http.ServeMux
+
+	mu        sync.Mutex
+	pipelines map[string]*registered
+	runs      map[string]*run
+	finished  []string // IDs of finished runs, oldest first
+	closed    bool     // set by Shutdown
+
+	ctx    context.Context // cancelled when Shutdown gives up waiting
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type registered struct {
+	pipeline   *pipeline.Pipeline
+	inputTypes []reflect.Type
+}
+
+type run struct {
+	id       string
+	pipeline string
+	labels   map[string]string
+	start    time.Time
+	cancel   context.CancelFunc
+	done     bool
+	result   *pipeline.Result
+	err      error
+}
+
+// NewServer creates a Server with no pipelines.
+func NewServer() *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		mux:       http.NewServeMux(),
+		pipelines: make(map[string]*registered),
+		runs:      make(map[string]*run),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	s.mux.HandleFunc("GET /pipelines", s.listPipelines)
+	s.mux.HandleFunc("POST /pipelines/{name}/runs", s.startRun)
+	s.mux.HandleFunc("GET /runs/{id}", s.runStatus)
+	s.mux.HandleFunc("GET /runs/{id}/outputs", s.runOutputs)
+	s.mux.HandleFunc("POST /runs/cancel", s.cancelRuns)
+	return s
+}
+
+// Register exposes p under name. inputs are examples of the JSON inputs a run
+// request passes, by position: each one is decoded into a value of the type of
+// the corresponding example and added after p's initial inputs.
+func (s *Server) Register(name string, p *pipeline.Pipeline, inputs ...interface{}) error {
+	types := make([]reflect.Type, len(inputs))
+	for i, in := range inputs {
+		if types[i] = reflect.TypeOf(in); types[i] == nil {
+			return fmt.Errorf("pipelinehttp: pipeline %s: input %d: %w", name, i, pipeline.ErrUntypedNil)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.pipelines[name]; exists {
+		return fmt.Errorf("pipelinehttp: pipeline %s already registered", name)
+	}
+	s.pipelines[name] = &registered{pipeline: p, inputTypes: types}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Shutdown rejects new runs with 503 Service Unavailable and waits for the runs in
+// progress to finish. If ctx is done first, their contexts are cancelled and ctx's
+// error is returned once they have returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// CancelRuns cancels the contexts of the runs in progress of pipeline, or of every
+// pipeline if it is empty, whose labels include all of labels. It returns the IDs
+// of the cancelled runs, which finish failed with code CANCELLED.
+func (s *Server) CancelRuns(pipeline string, labels map[string]string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := []string{}
+	for id, rn := range s.runs {
+		if rn.done || (pipeline != "" && rn.pipeline != pipeline) || !matchLabels(rn.labels, labels) {
+			continue
+		}
+		rn.cancel()
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func matchLabels(have, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := have[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// PipelineInfo describes a registered pipeline in the GET /pipelines response.
+type PipelineInfo struct {
+	Name   string   `json:"name"`
+	Inputs []string `json:"inputs"` // Go types of the inputs a run request passes.
+}
+
+func (s *Server) listPipelines(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	infos := make([]PipelineInfo, 0, len(s.pipelines))
+	for name, reg := range s.pipelines {
+		info := PipelineInfo{Name: name, Inputs: []string{}}
+		for _, t := range reg.inputTypes {
+			info.Inputs = append(info.Inputs, t.String())
+		}
+		infos = append(infos, info)
+	}
+	s.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// RunRequest is the body of POST /pipelines/{name}/runs.
+type RunRequest struct {
+	Inputs []json.RawMessage `json:"inputs"`
+	Labels map[string]string `json:"labels,omitempty"` // For selecting the run in POST /runs/cancel.
+}
+
+// RunStatus is the body of GET /runs/{id}, and of the POST response without Steps.
+type RunStatus struct {
+	RunID    string                 `json:"run_id"`
+	Pipeline string                 `json:"pipeline"`
+	Status   pipeline.RunStatus     `json:"status"`
+	Labels   map[string]string      `json:"labels,omitempty"`
+	Start    time.Time              `json:"start"`
+	Error    string                 `json:"error,omitempty"`
+	Code     pipeline.ErrorCode     `json:"code,omitempty"`
+	Steps    []pipeline.RunStepInfo `json:"steps,omitempty"`
+}
+
+func (s *Server) startRun(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	s.mu.Lock()
+	reg, ok := s.pipelines[name]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("pipeline %s not found", name))
+		return
+	}
+
+	var req RunRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDefinitionSize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid run request: %w", err))
+		return
+	}
+	inputs, err := decodeInputs(req.Inputs, reg.inputTypes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id := pipeline.NewRunID()
+	ctx, cancel := context.WithCancel(pipeline.WithRunID(s.ctx, id))
+	rn := &run{id: id, pipeline: name, labels: req.Labels, start: time.Now(), cancel: cancel}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		cancel()
+		writeError(w, http.StatusServiceUnavailable, errors.New("server is shutting down"))
+		return
+	}
+	s.runs[rn.id] = rn
+	s.wg.Add(1)
+	s.mu.Unlock()
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		result, err := reg.pipeline.Run(ctx, inputs...)
+		s.finish(rn, result, err)
+	}()
+	writeJSON(w, http.StatusAccepted, RunStatus{RunID: rn.id, Pipeline: name, Status: pipeline.RunStatusRunning, Labels: rn.labels, Start: rn.start})
+}
+
+func decodeInputs(raw []json.RawMessage, types []reflect.Type) ([]interface{}, error) {
+	if len(raw) != len(types) {
+		return nil, fmt.Errorf("expected %d inputs, got %d", len(types), len(raw))
+	}
+	inputs := make([]interface{}, len(raw))
+	for i, data := range raw {
+		ptr := reflect.New(types[i])
+		if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("input %d is not a valid %s: %w", i, types[i], err)
+		}
+		inputs[i] = ptr.Elem().Interface()
+	}
+	return inputs, nil
+}
+
+func (s *Server) finish(rn *run, result *pipeline.Result, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rn.done, rn.result, rn.err = true, result, err
+	s.finished = append(s.finished, rn.id)
+	maxRuns := s.MaxRuns
+	if maxRuns <= 0 {
+		maxRuns = DefaultMaxRuns
+	}
+	for len(s.finished) > maxRuns {
+		delete(s.runs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+// lookupRun returns a snapshot of the run with the ID in the request path, or
+// answers 404 Not Found.
+func (s *Server) lookupRun(w http.ResponseWriter, r *http.Request) (run, bool) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	rn, ok := s.runs[id]
+	var snapshot run
+	if ok {
+		snapshot = *rn
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %s not found", id))
+	}
+	return snapshot, ok
+}
+
+func (s *Server) runStatus(w http.ResponseWriter, r *http.Request) {
+	rn, ok := s.lookupRun(w, r)
+	if !ok {
+		return
+	}
+	status := RunStatus{RunID: rn.id, Pipeline: rn.pipeline, Status: pipeline.RunStatusRunning, Labels: rn.labels, Start: rn.start}
+	if rn.done {
+		status.Status = pipeline.RunStatusSucceeded
+		if rn.err != nil {
+			status.Status, status.Error, status.Code = pipeline.RunStatusFailed, rn.err.Error(), pipeline.ErrorCodeOf(rn.err)
+		}
+		for _, step := range rn.result.Steps {
+			info := pipeline.RunStepInfo{Name: step.Name, Status: step.Status(), Duration: step.Duration}
+			if step.Err != nil {
+				info.Err = step.Err.Error()
+			}
+			status.Steps = append(status.Steps, info)
+		}
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) runOutputs(w http.ResponseWriter, r *http.Request) {
+	rn, ok := s.lookupRun(w, r)
+	if !ok {
+		return
+	}
+	if !rn.done {
+		writeError(w, http.StatusConflict, fmt.Errorf("run %s is still in progress", rn.id))
+		return
+	}
+	data, err := json.Marshal(rn.result.Outputs())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("outputs of run %s cannot be encoded: %w", rn.id, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// CancelRequest is the body of POST /runs/cancel. Empty fields match every run.
+type CancelRequest struct {
+	Pipeline string            `json:"pipeline,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+// CancelResponse is the answer to POST /runs/cancel.
+type CancelResponse struct {
+	Cancelled []string `json:"cancelled"` // IDs of the cancelled runs.
+}
+
+func (s *Server) cancelRuns(w http.ResponseWriter, r *http.Request) {
+	var req CancelRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDefinitionSize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid cancel request: %w", err))
+		return
+	}
+	if req.Pipeline != "" {
+		s.mu.Lock()
+		_, ok := s.pipelines[req.Pipeline]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("pipeline %s not found", req.Pipeline))
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, CancelResponse{Cancelled: s.CancelRuns(req.Pipeline, req.Labels)})
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,62 @@
+// Package pipelinehttp serves pipeline operations over HTTP.
+package pipelinehttp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"pipeline/pipeline"
+)
+
+// maxDefinitionSize bounds the request bodies holding pipeline definitions.
+const maxDefinitionSize = 1 << 20
+
+// ValidateResponse is the JSON body returned by ValidateHandler.
+type ValidateResponse struct {
+	Valid  bool                       `json:"valid"` // No issue is an error; warnings may remain.
+	Issues []pipeline.ValidationIssue `json:"issues"`
+	Error  string                     `json:"error,omitempty"` // Set if the definition could not be parsed.
+}
+
+// ValidateHandler returns a handler checking the YAML or JSON pipeline definition
+// POSTed to it against registry, with pipeline.ValidateDefinition, without
+// registering or running it. inputs stand for the initial inputs the pipelines are
+// run with. It answers 200 with a ValidateResponse listing every error and warning,
+// or 400 if the definition cannot be parsed, so config repositories can check
+// definitions before merging them.
+func ValidateHandler(registry *pipeline.Registry, inputs ...interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, ValidateResponse{Error: "method not allowed"})
+			return
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDefinitionSize))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ValidateResponse{Error: err.Error()})
+			return
+		}
+		issues, err := pipeline.ValidateDefinition(data, registry, inputs...)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ValidateResponse{Error: err.Error()})
+			return
+		}
+		resp := ValidateResponse{Valid: true, Issues: issues}
+		if resp.Issues == nil {
+			resp.Issues = []pipeline.ValidationIssue{}
+		}
+		for _, issue := range issues {
+			if !issue.Warning {
+				resp.Valid = false
+			}
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
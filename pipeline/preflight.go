@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PreflightCheck verifies a precondition, such as connectivity, credentials or free
+// disk space, before any step runs. A check with a Step applies only if that step
+// is part of the pipeline.
+type PreflightCheck struct {
+	Name  string
+	Step  string // Empty for pipeline-wide checks.
+	Check func(ctx context.Context) error
+}
+
+// PreflightError is returned when preflight checks fail; it lists every failure.
+type PreflightError struct {
+	Failures []error
+}
+
+func (e *PreflightError) Error() string {
+	msgs := make([]string, len(e.Failures))
+	for i, err := range e.Failures {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d preflight check(s) failed: %s", len(e.Failures), strings.Join(msgs, "; "))
+}
+
+func (e *PreflightError) Unwrap() []error {
+	return e.Failures
+}
+
+// AddPreflightCheck registers a check run by Preflight before every execution.
+func (p *Pipeline) AddPreflightCheck(check PreflightCheck) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.preflightChecks = append(p.preflightChecks, check)
+	p.logger.Debugf("Added preflight check %q", check.Name)
+}
+
+// Preflight runs every preflight check, even after a failure, and returns a
+// *PreflightError listing all failed checks. Execute and Run call it before the
+// first step and run no step if it fails.
+func (p *Pipeline) Preflight(ctx context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.preflight(ctx)
+}
+
+func (p *Pipeline) preflight(ctx context.Context) error {
+	var failures []error
+	for _, check := range p.preflightChecks {
+		if check.Step != "" {
+			if _, err := p.stepIndex(check.Step); err != nil {
+				continue
+			}
+		}
+		if err := check.Check(ctx); err != nil {
+			if check.Step != "" {
+				err = fmt.Errorf("preflight check %s for step %s: %w", check.Name, check.Step, err)
+			} else {
+				err = fmt.Errorf("preflight check %s: %w", check.Name, err)
+			}
+			p.logger.Errorf("%v", err)
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) > 0 {
+		return &PreflightError{Failures: failures}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package pipeline
+
+import "time"
+
+// ProgressKind tells what a ProgressEvent reports.
+type ProgressKind string
+
+const (
+	ProgressStepStarted  ProgressKind = "step_started"
+	ProgressStepFinished ProgressKind = "step_finished"
+	// ProgressStepWaiting reports a started step still waiting for its
+	// StepConfig.WaitFor condition, after every check; Duration is the time waited.
+	ProgressStepWaiting ProgressKind = "step_waiting"
+	// ProgressStepSkipped reports a step completed without running: disabled,
+	// skipped by its condition, restored from a checkpoint or a lazy step never requested.
+	ProgressStepSkipped ProgressKind = "step_skipped"
+	// ProgressRunFinished is the last event of a run, whatever its outcome.
+	ProgressRunFinished ProgressKind = "run_finished"
+)
+
+// ProgressEvent reports the progress of a run to the functions registered with OnProgress.
+type ProgressEvent struct {
+	Kind     ProgressKind
+	Pipeline string // PipelineConfig.Name
+	RunID    string
+	Step     string        // Empty for ProgressRunFinished.
+	Duration time.Duration // Of the step for ProgressStepFinished, of the wait for ProgressStepWaiting.
+	Err      error         // Of the step for ProgressStepFinished, of the run for ProgressRunFinished.
+
+	// Completed counts the steps finished or skipped so far, out of Total: the
+	// main steps (adapter steps included), assertions and teardown steps. A run
+	// stopped by a failure finishes with fewer than Total steps completed.
+	Completed int
+	Total     int
+	Percent   float64 // 100 * Completed / Total, and 100 for ProgressRunFinished.
+}
+
+// OnProgress registers fn to be called, on the run's goroutine, as steps start,
+// finish or are skipped and when the run finishes. fn must return quickly.
+func (p *Pipeline) OnProgress(fn func(ProgressEvent)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if fn != nil {
+		p.progressFns = append(p.progressFns, fn)
+	}
+}
+
+// progress counts completed steps and reports the event to the OnProgress functions.
+func (p *Pipeline) progress(kind ProgressKind, step string, duration time.Duration, err error) {
+	if kind == ProgressStepFinished || kind == ProgressStepSkipped {
+		p.progressDone++
+	}
+	if len(p.progressFns) == 0 {
+		return
+	}
+	event := ProgressEvent{
+		Kind:      kind,
+		Pipeline:  p.config.Name,
+		RunID:     p.currentRunID,
+		Step:      step,
+		Duration:  duration,
+		Err:       err,
+		Completed: p.progressDone,
+		Total:     p.progressTotal,
+		Percent:   100,
+	}
+	if kind != ProgressRunFinished && event.Total > 0 {
+		event.Percent = 100 * float64(event.Completed) / float64(event.Total)
+	}
+	for _, fn := range p.progressFns {
+		fn(event)
+	}
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import "time"
+
+// StepRecord describes the execution of a single step.
+type StepRecord struct {
+	Name      string
+	Start     time.Time
+	End       time.Time
+	Duration  time.Duration
+	Outputs   []interface{}
+	Err       error
+	Skipped   bool
+	Disabled  bool // The step was skipped because it is disabled.
+	Resumed   bool // Outputs were restored from a checkpoint instead of running the step.
+	Cached    bool // Outputs were taken from the step cache instead of running the step.
+	Teardown  bool // The step was registered with AddTeardownStep.
+	Assertion bool // The step was registered with AddAssertStep.
+
+	// Waited is the time spent waiting for StepConfig.WaitFor, included in Duration.
+	Waited time.Duration
+
+	// Conversions lists the arguments converted by a registered converter on the
+	// way to the step, see Pipeline.RegisterConverter.
+	Conversions []Conversion
+
+	inputsHash string // audit hash of the step's arguments, see PipelineConfig.Audit
+}
+
+// Status returns a short status of the step: failed, disabled, skipped, resumed,
+// cached or passed.
+func (r StepRecord) Status() string {
+	switch {
+	case r.Err != nil:
+		return "failed"
+	case r.Disabled:
+		return "disabled"
+	case r.Skipped:
+		return "skipped"
+	case r.Resumed:
+		return "resumed"
+	case r.Cached:
+		return "cached"
+	default:
+		return "passed"
+	}
+}
+
+// Result is returned by Execute and holds one record per step, in execution order.
+type Result struct {
+	RunID string // Random UUID identifying the run, as recorded in PipelineConfig.RunRegistry.
+	Steps []StepRecord
+	Audit []AuditRecord // Hash chain of Steps, with PipelineConfig.Audit.
+
+	// Triggered lists the runs started by PipelineConfig.Triggers.
+	Triggered []TriggeredRun
+
+	outputs map[string][]interface{}
+}
+
+// Outputs returns the outputs of every step, keyed by step name and restricted
+// to config.OutputFilter if one is set.
+func (r *Result) Outputs() map[string][]interface{} {
+	return r.outputs
+}
+
+// Step returns the record of the step named name, if it was executed or skipped.
+func (r *Result) Step(name string) (*StepRecord, bool) {
+	for i := range r.Steps {
+		if r.Steps[i].Name == name {
+			return &r.Steps[i], true
+		}
+	}
+	return nil, false
+}
+
+// Duration returns the time from the first step's start to the last step's end.
+func (r *Result) Duration() time.Duration {
+	if len(r.Steps) == 0 {
+		return 0
+	}
+	return r.Steps[len(r.Steps)-1].End.Sub(r.Steps[0].Start)
+}
@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// StepRunner is implemented by struct-based steps, which hold their own
+// configuration and resources. A StepRunner may be passed to AddStep instead of a
+// function; it reads its inputs and records its outputs through the StepContext.
+// If it also implements StepIniter or StepCloser, Init is called before its first
+// Run in an execution and Close once the execution is over. Concurrent calls to
+// Pipeline.Run share the StepRunner, so each of them calls Init and Close on it.
+type StepRunner interface {
+	Run(ctx *StepContext) error
+}
+
+type StepIniter interface {
+	Init() error
+}
+
+type StepCloser interface {
+	Close() error
+}
+
+// StepContext gives a StepRunner access to the running pipeline.
+type StepContext struct {
+	context.Context
+
+	Pipeline string
+	Step     string
+	Logger   Logger
+
+	exec    *ExecutionContext
+	outputs []interface{}
+}
+
+// Get stores in the value pointed to by ptr the latest value of its type (or, for an
+// interface, the latest implementation of it) from the execution context.
+func (c *StepContext) Get(ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("Get requires a non-nil pointer, got %T", ptr)
+	}
+	t := v.Elem().Type()
+	vals := c.exec.candidates(t)
+	if len(vals) == 0 {
+		return fmt.Errorf("no values found for type %s", t)
+	}
+	v.Elem().Set(vals[len(vals)-1])
+	return nil
+}
+
+// Set records the step's outputs, which later steps can bind like function returns.
+func (c *StepContext) Set(outputs ...interface{}) {
+	c.outputs = append(c.outputs, outputs...)
+}
+
+func isRunner(step Step) bool {
+	_, ok := step.Callable.(StepRunner)
+	return ok
+}
+
+// executeRunner runs a StepRunner step, initializing it first if needed, and stores
+// the outputs it set.
+func (p *Pipeline) executeRunner(step Step, runner StepRunner) ([]interface{}, error) {
+	if !p.openRunners[step.Name] {
+		if initer, ok := runner.(StepIniter); ok {
+			if err := initer.Init(); err != nil {
+				return nil, fmt.Errorf("init: %w", err)
+			}
+		}
+		if p.openRunners == nil {
+			p.openRunners = make(map[string]bool)
+		}
+		p.openRunners[step.Name] = true
+		p.openRunnerOrder = append(p.openRunnerOrder, step)
+	}
+
+	ctx := p.runCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sc := &StepContext{Context: ctx, Pipeline: p.config.Name, Step: step.Name, Logger: p.logger, exec: p.context}
+	run := reflect.ValueOf(func() error { return runner.Run(sc) })
+	if _, err := p.callStep(step, run, nil); err != nil {
+		return nil, err
+	}
+
+	for _, out := range sc.outputs {
+		if out != nil {
+			p.context.storeValue(reflect.ValueOf(out))
+		}
+	}
+	p.stepOutputs[step.Name] = append(p.stepOutputs[step.Name], sc.outputs...)
+	p.logger.Debugf("Step %q produced %d outputs", step.Name, len(sc.outputs))
+	return sc.outputs, nil
+}
+
+// closeRunners closes the StepRunner steps run during this execution, in reverse
+// order of their first run, and returns their failures.
+func (p *Pipeline) closeRunners() error {
+	var failures []error
+	for i := len(p.openRunnerOrder) - 1; i >= 0; i-- {
+		step := p.openRunnerOrder[i]
+		closer, ok := step.Callable.(StepCloser)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			err = fmt.Errorf("closing step %s: %w", step.Name, err)
+			p.logger.Errorf("%v", err)
+			failures = append(failures, err)
+		}
+	}
+	p.openRunners, p.openRunnerOrder = nil, nil
+
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return failures[0]
+	default:
+		return &MultiError{Errors: failures}
+	}
+}
@@ -0,0 +1,216 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy decides what a Scheduler does when a job is due while its previous
+// run is still in progress.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the activation.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs it once the runs before it have finished.
+	OverlapQueue
+	// OverlapAllow starts it right away, concurrently with the previous run.
+	OverlapAllow
+)
+
+// ErrSchedulerStopped is returned when adding a job to a Scheduler that has shut down.
+var ErrSchedulerStopped = errors.New("scheduler stopped")
+
+// Scheduler runs pipelines periodically, on cron expressions or fixed intervals.
+type Scheduler struct {
+	logger Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*scheduledJob
+	ctx     context.Context // cancelled when shutdown gives up waiting
+	cancel  context.CancelFunc
+	stop    chan struct{} // closed by Shutdown
+	stopped bool
+	loops   sync.WaitGroup // one per job
+	runs    sync.WaitGroup // one per run in progress
+}
+
+type scheduledJob struct {
+	name     string
+	pipeline *Pipeline
+	schedule Schedule
+	policy   OverlapPolicy
+
+	mu      sync.Mutex
+	running int
+	queued  int
+}
+
+// NewScheduler creates a Scheduler logging to logger, or to the global logger if
+// logger is nil. Jobs start as soon as they are added.
+func NewScheduler(logger Logger) *Scheduler {
+	if logger == nil {
+		logger = globalLogger
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		logger: logger,
+		jobs:   make(map[string]*scheduledJob),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+	}
+}
+
+// AddCron runs p with Run, without extra inputs, at the times matched by the cron
+// expression expr (see ParseCron), in the local time zone.
+func (s *Scheduler) AddCron(name, expr string, p *Pipeline, policy OverlapPolicy) error {
+	schedule, err := ParseCron(expr, nil)
+	if err != nil {
+		return fmt.Errorf("scheduler job %s: %w", name, err)
+	}
+	return s.Add(name, schedule, p, policy)
+}
+
+// AddInterval runs p every interval, starting one interval from now.
+func (s *Scheduler) AddInterval(name string, interval time.Duration, p *Pipeline, policy OverlapPolicy) error {
+	if interval <= 0 {
+		return fmt.Errorf("scheduler job %s: interval must be positive", name)
+	}
+	return s.Add(name, Every(interval), p, policy)
+}
+
+// Add runs p on schedule under the job name, which must be unique.
+func (s *Scheduler) Add(name string, schedule Schedule, p *Pipeline, policy OverlapPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrSchedulerStopped
+	}
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("scheduler job %s already exists", name)
+	}
+	job := &scheduledJob{name: name, pipeline: p, schedule: schedule, policy: policy}
+	s.jobs[name] = job
+	s.loops.Add(1)
+	go s.loop(job)
+	s.logger.Debugf("Scheduled job %q", name)
+	return nil
+}
+
+// Shutdown stops scheduling runs and waits for the runs in progress to finish. If
+// ctx is done first, their contexts are cancelled and ctx's error is returned once
+// they have returned. Queued runs are dropped.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+	}
+	s.mu.Unlock()
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// CancelQueued drops the runs of the job name queued behind its run in progress
+// by OverlapQueue, and returns how many were dropped. The run in progress goes on.
+func (s *Scheduler) CancelQueued(name string) (int, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("scheduler job %s not found", name)
+	}
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	dropped := job.queued
+	job.queued = 0
+	if dropped > 0 {
+		s.logger.Infof("Cancelled %d queued runs of job %q", dropped, name)
+	}
+	return dropped, nil
+}
+
+func (s *Scheduler) loop(job *scheduledJob) {
+	defer s.loops.Done()
+	next := job.schedule.Next(time.Now())
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.activate(job)
+		next = job.schedule.Next(next)
+		if now := time.Now(); next.Before(now) {
+			// Activations missed while the process was suspended are not caught up.
+			next = job.schedule.Next(now)
+		}
+	}
+	s.logger.Warnf("Scheduler job %q has no further activations", job.name)
+}
+
+// activate starts, queues or skips a run of job according to its overlap policy.
+func (s *Scheduler) activate(job *scheduledJob) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.running > 0 {
+		switch job.policy {
+		case OverlapSkip:
+			s.logger.Warnf("Skipping run of job %q: previous run still in progress", job.name)
+			return
+		case OverlapQueue:
+			job.queued++
+			s.logger.Infof("Queued run of job %q behind the run in progress", job.name)
+			return
+		}
+	}
+	job.running++
+	s.runs.Add(1)
+	go s.run(job)
+}
+
+func (s *Scheduler) run(job *scheduledJob) {
+	defer s.runs.Done()
+	for {
+		result, err := job.pipeline.Run(s.ctx)
+		if err != nil {
+			s.logger.Errorf("Scheduled run %s of job %q failed: %v", result.RunID, job.name, err)
+		} else {
+			s.logger.Infof("Scheduled run %s of job %q succeeded", result.RunID, job.name)
+		}
+
+		job.mu.Lock()
+		select {
+		case <-s.stop:
+			job.queued = 0
+		default:
+		}
+		if job.queued == 0 {
+			job.running--
+			job.mu.Unlock()
+			return
+		}
+		job.queued--
+		job.mu.Unlock()
+	}
+}
@@ -0,0 +1,250 @@
+// Package stepcheck provides an analyzer that cross-checks step names used in
+// StepOrder, OutputFilter, StepConfigs keys and ArgBindings against the names
+// registered with AddStep in the same package.
+package stepcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const pipelinePkgPath = "pipeline/pipeline"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "stepcheck",
+	Doc:      "report step names in pipeline configs that no AddStep call registers",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// addStepMethods map the methods registering a step to the position of its name argument.
+var addStepMethods = map[string]int{
+	"AddStep":               0,
+	"AddFanOutStep":         0,
+	"AddAdaptiveFanOutStep": 0,
+	"AddTeardownStep":       0,
+	"AddAssertStep":         0,
+	"InsertStepBefore":      1,
+	"InsertStepAfter":       1,
+}
+
+// nameRef is a string literal expected to name a registered step.
+type nameRef struct {
+	pos   token.Pos
+	name  string
+	where string
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	registered := make(map[string]bool)
+	var refs []nameRef
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+		(*ast.CompositeLit)(nil),
+		(*ast.AssignStmt)(nil),
+		(*ast.IndexExpr)(nil),
+	}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if name, ok := addStepName(pass, n); ok {
+				registered[name] = true
+			}
+		case *ast.CompositeLit:
+			refs = append(refs, compositeRefs(pass, n)...)
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if i >= len(n.Rhs) {
+					break
+				}
+				if field, ok := configField(pass, lhs); ok {
+					refs = append(refs, fieldRefs(pass, field, n.Rhs[i])...)
+				}
+			}
+		case *ast.IndexExpr:
+			if field, ok := configField(pass, n.X); ok && field == "StepConfigs" {
+				refs = append(refs, stringsIn(pass, n.Index, "StepConfigs key")...)
+			}
+		}
+	})
+
+	// Pipelines assembled in another package cannot be checked here.
+	if len(registered) == 0 {
+		return nil, nil
+	}
+	for _, ref := range refs {
+		if !registered[ref.name] {
+			pass.Reportf(ref.pos, "%s refers to step %q which is not added with AddStep in this package", ref.where, ref.name)
+		}
+	}
+	return nil, nil
+}
+
+// addStepName returns the constant name passed to (*pipeline.Pipeline).AddStep and friends.
+func addStepName(pass *analysis.Pass, call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	arg, ok := addStepMethods[sel.Sel.Name]
+	if !ok || len(call.Args) <= arg {
+		return "", false
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pipelinePkgPath {
+		return "", false
+	}
+	return stringConst(pass, call.Args[arg])
+}
+
+// configField reports the field name if expr selects a field of pipeline.PipelineConfig.
+func configField(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	v, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Var)
+	if !ok || !v.IsField() || v.Pkg() == nil || v.Pkg().Path() != pipelinePkgPath {
+		return "", false
+	}
+	if !isPipelineType(pass.TypesInfo.TypeOf(sel.X), "PipelineConfig") {
+		return "", false
+	}
+	return v.Name(), true
+}
+
+// compositeRefs extracts step names from PipelineConfig and ArgBinding literals.
+func compositeRefs(pass *analysis.Pass, lit *ast.CompositeLit) []nameRef {
+	t := pass.TypesInfo.TypeOf(lit)
+	switch {
+	case isPipelineType(t, "PipelineConfig"):
+		var refs []nameRef
+		for field, value := range keyedFields(lit) {
+			refs = append(refs, fieldRefs(pass, field, value)...)
+		}
+		return refs
+	case isPipelineType(t, "ArgBinding"):
+		fields := keyedFields(lit)
+		source, ok := fields["Source"]
+		if !ok {
+			return nil
+		}
+		switch sourceName(pass, source) {
+		case "ArgSourceFunctionOutput":
+			if name, ok := fields["Name"]; ok {
+				return stringsIn(pass, name, "ArgBinding.Name")
+			}
+		case "ArgSourceCollect":
+			if names, ok := fields["Names"]; ok {
+				return stringsIn(pass, names, "ArgBinding.Names")
+			}
+		}
+	}
+	return nil
+}
+
+// fieldRefs extracts step names from the value given to a PipelineConfig field.
+func fieldRefs(pass *analysis.Pass, field string, value ast.Expr) []nameRef {
+	switch field {
+	case "StepOrder":
+		return stringsIn(pass, value, field)
+	case "OutputFilter":
+		// Glob and "re:" entries are patterns, not step names.
+		var refs []nameRef
+		for _, ref := range stringsIn(pass, value, field) {
+			if !strings.HasPrefix(ref.name, "re:") && !strings.ContainsAny(ref.name, `*?[\`) {
+				refs = append(refs, ref)
+			}
+		}
+		return refs
+	case "StepConfigs":
+		var refs []nameRef
+		if m, ok := value.(*ast.CompositeLit); ok {
+			for _, elt := range m.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					refs = append(refs, stringsIn(pass, kv.Key, "StepConfigs key")...)
+				}
+			}
+		}
+		return refs
+	}
+	return nil
+}
+
+func keyedFields(lit *ast.CompositeLit) map[string]ast.Expr {
+	fields := make(map[string]ast.Expr)
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if ident, ok := kv.Key.(*ast.Ident); ok {
+			fields[ident.Name] = kv.Value
+		}
+	}
+	return fields
+}
+
+func sourceName(pass *analysis.Pass, expr ast.Expr) string {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return ""
+	}
+	if c, ok := pass.TypesInfo.Uses[ident].(*types.Const); ok && c.Pkg() != nil && c.Pkg().Path() == pipelinePkgPath {
+		return c.Name()
+	}
+	return ""
+}
+
+// stringsIn returns the string constants of expr, or of the elements of a []string literal.
+func stringsIn(pass *analysis.Pass, expr ast.Expr, where string) []nameRef {
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		var refs []nameRef
+		for _, elt := range lit.Elts {
+			refs = append(refs, stringsIn(pass, elt, where)...)
+		}
+		return refs
+	}
+	if s, ok := stringConst(pass, expr); ok {
+		return []nameRef{{pos: expr.Pos(), name: s, where: where}}
+	}
+	return nil
+}
+
+func stringConst(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func isPipelineType(t types.Type, name string) bool {
+	if t == nil {
+		return false
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pipelinePkgPath && obj.Name() == name
+}
@@ -0,0 +1,143 @@
+package pipeline
+
+import (
+	"reflect"
+	"time"
+)
+
+// stream is a streaming step running in the background.
+type stream struct {
+	step  Step
+	start time.Time
+	recvs []reflect.Value // receive-only ends of the channels the step sends on
+	done  chan struct{}
+	err   error
+}
+
+// isStreamingStep reports whether fnType is a streaming step: it has at least one
+// send-only channel parameter (chan<- T) and returns nothing or only an error.
+// The engine creates those channels, stores their receive-only ends (<-chan T) as
+// the step's outputs and runs the step in the background, closing its channels
+// when it returns, so later steps taking <-chan T consume the values as they are
+// produced instead of after the step finishes.
+func isStreamingStep(fnType reflect.Type) bool {
+	switch {
+	case fnType == nil || fnType.Kind() != reflect.Func:
+		return false
+	case fnType.NumOut() > 1:
+		return false
+	case fnType.NumOut() == 1 && fnType.Out(0) != errorType:
+		return false
+	}
+	for i := 0; i < fnType.NumIn(); i++ {
+		if isSendChan(fnType.In(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSendChan(t reflect.Type) bool {
+	return t.Kind() == reflect.Chan && t.ChanDir() == reflect.SendDir
+}
+
+// streamOutputTypes returns the output types of a streaming step as seen by the
+// steps after it: one <-chan T for each chan<- T parameter.
+func streamOutputTypes(fnType reflect.Type) []reflect.Type {
+	var outs []reflect.Type
+	for i := 0; i < fnType.NumIn(); i++ {
+		if in := fnType.In(i); isSendChan(in) {
+			outs = append(outs, reflect.ChanOf(reflect.RecvDir, in.Elem()))
+		}
+	}
+	return outs
+}
+
+// startStream creates the channels of a streaming step, starts it and stores the
+// receiving ends. args holds the resolved arguments, with zero values in place of
+// the channel parameters.
+func (p *Pipeline) startStream(step Step, fnValue reflect.Value, args []reflect.Value) []interface{} {
+	buffer := 0
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok {
+		buffer = stepCfg.StreamBuffer
+	}
+
+	fnType := fnValue.Type()
+	s := &stream{step: step, start: time.Now(), done: make(chan struct{})}
+	var sends []reflect.Value
+	for i := range args {
+		in := fnType.In(i)
+		if !isSendChan(in) {
+			continue
+		}
+		ch := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, in.Elem()), buffer)
+		args[i] = ch.Convert(in)
+		sends = append(sends, ch)
+		s.recvs = append(s.recvs, ch.Convert(reflect.ChanOf(reflect.RecvDir, in.Elem())))
+	}
+
+	release := p.reserveCPUs(step)
+	go func() {
+		defer close(s.done)
+		defer release()
+		_, s.err = p.callStep(step, fnValue, args)
+		for _, ch := range sends {
+			ch.Close()
+		}
+	}()
+	p.streams = append(p.streams, s)
+
+	p.context.StoreResults(s.recvs)
+	outputs := make([]interface{}, len(s.recvs))
+	for i, ch := range s.recvs {
+		outputs[i] = ch.Interface()
+	}
+	p.stepOutputs[step.Name] = append(p.stepOutputs[step.Name], outputs...)
+	p.logger.Debugf("Step %q started streaming %d outputs", step.Name, len(outputs))
+	return outputs
+}
+
+// waitStreams waits for every streaming step started so far, discarding the values
+// no step consumed, and completes their records in result. It returns their failures.
+func (p *Pipeline) waitStreams(result *Result) error {
+	var failures []error
+	for _, s := range p.streams {
+		for _, ch := range s.recvs {
+			for {
+				if _, ok := ch.Recv(); !ok {
+					break
+				}
+			}
+		}
+		<-s.done
+
+		duration := time.Since(s.start)
+		p.notifyStepFinished(s.step, duration, s.err)
+		for i := len(result.Steps) - 1; i >= 0; i-- {
+			record := &result.Steps[i]
+			if record.Name != s.step.Name || record.Teardown {
+				continue
+			}
+			record.End = s.start.Add(duration)
+			record.Duration = duration
+			record.Err = p.stepError(s.step, i, s.err)
+			if record.Err != nil && p.severity(s.step) == SeverityBestEffort {
+				p.logger.Warnf("Best-effort step %q failed: %v", s.step.Name, record.Err)
+			} else if record.Err != nil {
+				p.logger.Errorf("Step %q failed: %v", s.step.Name, record.Err)
+				failures = append(failures, record.Err)
+			}
+			break
+		}
+	}
+	p.streams = nil
+
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return failures[0]
+	default:
+		return &MultiError{Errors: failures}
+	}
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStreamingStepFeedsConsumer(t *testing.T) {
+	p := NewPipeline(nil, discardLogger)
+	p.AddStep("produce", func(out chan<- int) {
+		for i := 1; i <= 5; i++ {
+			out <- i
+		}
+	})
+	p.AddStep("sum", func(in <-chan int) int {
+		total := 0
+		for n := range in {
+			total += n
+		}
+		return total
+	})
+
+	result, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := result.Steps[1].Outputs; len(got) != 1 || got[0] != 15 {
+		t.Errorf("sum outputs = %v, want [15]", got)
+	}
+}
+
+func TestStreamingStepWithoutConsumer(t *testing.T) {
+	p := NewPipeline(nil, discardLogger)
+	sent := 0
+	p.AddStep("produce", func(out chan<- string) {
+		for i := 0; i < 100; i++ {
+			out <- "x"
+			sent++
+		}
+	})
+
+	if _, err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if sent != 100 {
+		t.Errorf("produce sent %d values, want 100", sent)
+	}
+}
+
+func TestStreamingStepFailure(t *testing.T) {
+	p := NewPipeline(nil, discardLogger)
+	errBoom := errors.New("boom")
+	p.AddStep("produce", func(out chan<- int) error {
+		out <- 1
+		return errBoom
+	})
+	var got []int
+	p.AddStep("consume", func(in <-chan int) {
+		for n := range in {
+			got = append(got, n)
+		}
+	})
+
+	result, err := p.Run(context.Background())
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Run = %v, want %v", err, errBoom)
+	}
+	if !errors.Is(result.Steps[0].Err, errBoom) {
+		t.Errorf("record of produce has error %v, want %v", result.Steps[0].Err, errBoom)
+	}
+	if len(got) != 1 || got[0] != 1 {
+		t.Errorf("consume received %v, want [1]", got)
+	}
+}
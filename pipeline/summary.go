@@ -0,0 +1,138 @@
+package pipeline
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var statusIcons = map[string]string{
+	"failed":   "❌",
+	"disabled": "⏸️",
+	"skipped":  "⏭️",
+	"resumed":  "↩️",
+	"cached":   "♻️",
+	"passed":   "✅",
+}
+
+// WriteMarkdownSummary writes the run as a Markdown table with one row per step, in
+// the format of GitHub Actions job summaries (also rendered by GitLab and Gitea).
+func (r *Result) WriteMarkdownSummary(w io.Writer, title string) error {
+	failed := 0
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			failed++
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", title)
+	fmt.Fprintf(&b, "%d step(s), %d failed, in %s\n\n", len(r.Steps), failed, r.Duration())
+	b.WriteString("| Step | Status | Duration | Details |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, step := range r.Steps {
+		status := step.Status()
+		name := markdownEscape(step.Name)
+		switch {
+		case step.Teardown:
+			name += " _(teardown)_"
+		case step.Assertion:
+			name += " _(assertion)_"
+		}
+		details := ""
+		if step.Err != nil {
+			details = fmt.Sprintf("`%s` %s", ErrorCodeOf(step.Err), markdownEscape(step.Err.Error()))
+		}
+		fmt.Fprintf(&b, "| %s | %s %s | %s | %s |\n", name, statusIcons[status], status, step.Duration, details)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape keeps s on a single table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+// AppendGitHubStepSummary appends the Markdown summary of the run to the file named by
+// GITHUB_STEP_SUMMARY, which GitHub Actions shows on the job's summary page. It does
+// nothing outside GitHub Actions, where the variable is not set.
+func (r *Result) AppendGitHubStepSummary(title string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("step summary: %w", err)
+	}
+	if err := r.WriteMarkdownSummary(f, title); err != nil {
+		f.Close()
+		return fmt.Errorf("step summary: %w", err)
+	}
+	return f.Close()
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"` // the ErrorCode of the failure
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitXML writes the run as a JUnit XML report with one test case per step,
+// so that CI systems (Jenkins, GitLab, GitHub via test-reporter actions) list step
+// results natively. Failed steps are failures and skipped steps are skipped tests;
+// suite is the name of the test suite and the class name of every case.
+func (r *Result) WriteJUnitXML(w io.Writer, suite string) error {
+	s := junitSuite{Name: suite, Tests: len(r.Steps), Time: junitSeconds(r.Duration().Seconds())}
+	for _, step := range r.Steps {
+		tc := junitCase{Name: step.Name, ClassName: suite, Time: junitSeconds(step.Duration.Seconds())}
+		switch {
+		case step.Err != nil:
+			s.Failures++
+			tc.Failure = &junitFailure{Message: step.Err.Error(), Type: string(ErrorCodeOf(step.Err)), Text: step.Err.Error()}
+		case step.Skipped:
+			s.Skipped++
+			tc.Skipped = &struct{}{}
+		}
+		s.Cases = append(s.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{s}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSeconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
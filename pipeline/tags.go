@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"maps"
+	"slices"
+)
+
+// StepOption sets part of a step's StepConfig when the step is added with AddStep.
+type StepOption func(*StepConfig)
+
+// WithDescription describes what the step does, for readers of Explain.
+func WithDescription(description string) StepOption {
+	return func(cfg *StepConfig) { cfg.Description = description }
+}
+
+// WithTags adds tags to the step, which PipelineConfig.IncludeTags and
+// ExcludeTags select steps by.
+func WithTags(tags ...string) StepOption {
+	return func(cfg *StepConfig) { cfg.Tags = append(cfg.Tags, tags...) }
+}
+
+// applyStepOptions applies opts to the StepConfig of the step name, creating it
+// if needed. Runs in progress keep reading the StepConfigs they started with, so
+// the map and the StepConfig are replaced rather than modified.
+func (p *Pipeline) applyStepOptions(name string, opts []StepOption) {
+	if len(opts) == 0 {
+		return
+	}
+	stepCfg := &StepConfig{}
+	if current, ok := p.config.StepConfigs[name]; ok && current != nil {
+		stepCfg = current.Clone()
+	}
+	for _, opt := range opts {
+		opt(stepCfg)
+	}
+	stepConfigs := maps.Clone(p.config.StepConfigs)
+	if stepConfigs == nil {
+		stepConfigs = make(map[string]*StepConfig)
+	}
+	stepConfigs[name] = stepCfg
+	p.config.StepConfigs = stepConfigs
+}
+
+// StepsTagged returns the names of the main steps having at least one of tags, in
+// definition order.
+func (p *Pipeline) StepsTagged(tags ...string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var names []string
+	for _, step := range p.steps {
+		if p.hasAnyTag(step.Name, tags) {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+func (p *Pipeline) hasAnyTag(name string, tags []string) bool {
+	stepCfg, ok := p.config.StepConfigs[name]
+	if !ok || stepCfg == nil {
+		return false
+	}
+	return slices.ContainsFunc(stepCfg.Tags, func(tag string) bool { return slices.Contains(tags, tag) })
+}
+
+// deselected reports whether the main step name is left out of the run by
+// PipelineConfig.IncludeTags or ExcludeTags, or by a partial run such as RunOnly.
+// Teardown and assert steps never are.
+func (p *Pipeline) deselected(name string) bool {
+	if p.unselected(name) {
+		return true
+	}
+	if len(p.config.IncludeTags) == 0 && len(p.config.ExcludeTags) == 0 {
+		return false
+	}
+	if stepPosition(p.steps, name) < 0 {
+		return false
+	}
+	if len(p.config.IncludeTags) > 0 && !p.hasAnyTag(name, p.config.IncludeTags) {
+		return true
+	}
+	return p.hasAnyTag(name, p.config.ExcludeTags)
+}
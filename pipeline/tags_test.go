@@ -0,0 +1,43 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestAddStepWithOptionsDuringRun(t *testing.T) {
+	p := NewPipeline(nil, discardLogger)
+	running := make(chan struct{})
+	p.AddStep("signal", func() { close(running) }, WithTags("base"))
+	for i := 0; i < 50; i++ {
+		p.AddStep(fmt.Sprintf("step-%d", i), func() {}, WithTags("base"))
+	}
+
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		<-running
+		for i := 0; i < 200; i++ {
+			p.AddStep(fmt.Sprintf("added-%d", i), func() {}, WithTags("fast"), WithDescription("added mid-run"))
+		}
+	}()
+	for stop := false; !stop; {
+		select {
+		case <-added:
+			stop = true
+		default:
+		}
+		if _, err := p.Run(context.Background()); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		p.DisableStep("signal")
+	}
+
+	if got := p.StepsTagged("fast"); len(got) != 200 {
+		t.Errorf("StepsTagged(fast) has %d steps, want the 200 added", len(got))
+	}
+	if got := p.StepsTagged("base"); len(got) != 51 {
+		t.Errorf("StepsTagged(base) has %d steps, want 51", len(got))
+	}
+}
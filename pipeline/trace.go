@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// traceEvent is an entry of the Chrome trace event format, also read by Perfetto.
+type traceEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	TS    int64                  `json:"ts"` // Microseconds.
+	Dur   int64                  `json:"dur"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes the step intervals of the run as a Chrome trace JSON file,
+// viewable in chrome://tracing or ui.perfetto.dev. Steps overlapping in time are
+// placed on separate lanes; skipped steps are shown as instant events.
+func (r *Result) WriteChromeTrace(w io.Writer) error {
+	var origin time.Time
+	if len(r.Steps) > 0 {
+		origin = r.Steps[0].Start
+	}
+
+	var laneEnds []time.Time
+	events := make([]traceEvent, 0, len(r.Steps))
+	for _, step := range r.Steps {
+		ev := traceEvent{
+			Name: step.Name,
+			Cat:  "step",
+			TS:   step.Start.Sub(origin).Microseconds(),
+			PID:  1,
+			Args: map[string]interface{}{},
+		}
+		switch {
+		case step.Skipped:
+			ev.Phase, ev.Scope = "i", "t"
+			ev.Args["skipped"] = true
+		default:
+			ev.Phase = "X"
+			ev.Dur = step.Duration.Microseconds()
+			if step.Resumed {
+				ev.Args["resumed"] = true
+			}
+		}
+		if step.Err != nil {
+			ev.Args["error"] = step.Err.Error()
+			ev.Args["code"] = ErrorCodeOf(step.Err)
+		}
+		ev.TID = traceLane(&laneEnds, step.Start, step.End)
+		events = append(events, ev)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+}
+
+// traceLane returns the first lane free at start and marks it busy until end.
+func traceLane(laneEnds *[]time.Time, start, end time.Time) int {
+	for i, busyUntil := range *laneEnds {
+		if !start.Before(busyUntil) {
+			(*laneEnds)[i] = end
+			return i + 1
+		}
+	}
+	*laneEnds = append(*laneEnds, end)
+	return len(*laneEnds)
+}
@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrUntrustedDefinition is returned when a pipeline definition is neither pinned
+// nor signed by a trusted key.
+var ErrUntrustedDefinition = errors.New("untrusted pipeline definition")
+
+// TrustList decides which pipeline definitions may be loaded: those whose content
+// hash is pinned, and those carrying an Ed25519 signature by one of the keys.
+type TrustList struct {
+	Hashes []string            // Hex SHA-256 digests of the exact definition bytes, see DefinitionHash.
+	Keys   []ed25519.PublicKey // Keys whose signatures over the definition bytes are accepted.
+}
+
+// DefinitionHash returns the hex SHA-256 digest of a definition, as pinned in TrustList.Hashes.
+func DefinitionHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks that data is pinned or that signature is a valid signature of it by
+// a trusted key. signature may be nil for pinned definitions.
+func (t *TrustList) Verify(data, signature []byte) error {
+	hash := DefinitionHash(data)
+	for _, pinned := range t.Hashes {
+		if strings.EqualFold(pinned, hash) {
+			return nil
+		}
+	}
+	if len(signature) == 0 {
+		return fmt.Errorf("%w: sha256 %s is not pinned and the definition is not signed", ErrUntrustedDefinition, hash)
+	}
+	for _, key := range t.Keys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, data, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature does not match any trusted key", ErrUntrustedDefinition)
+}
+
+// LoadTrustedPipeline is LoadPipeline for definitions verified against trust first.
+func LoadTrustedPipeline(data, signature []byte, trust *TrustList, registry *Registry, logger Logger) (*Pipeline, error) {
+	if err := trust.Verify(data, signature); err != nil {
+		return nil, err
+	}
+	return LoadPipeline(data, registry, logger)
+}
+
+// LoadTrustedPipelineFile is LoadTrustedPipeline reading the definition from path and
+// its base64-encoded signature, if there is one, from path + ".sig".
+func LoadTrustedPipelineFile(path string, trust *TrustList, registry *Registry, logger Logger) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pipeline definition: %w", err)
+	}
+	var signature []byte
+	encoded, err := os.ReadFile(path + ".sig")
+	switch {
+	case err == nil:
+		signature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil {
+			return nil, fmt.Errorf("read pipeline definition signature: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("read pipeline definition signature: %w", err)
+	}
+	return LoadTrustedPipeline(data, signature, trust, registry, logger)
+}
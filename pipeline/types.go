@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeRegistry maps Go types to stable names recorded in persisted state, such as
+// checkpoints and run history, instead of their Go names. A renamed or moved type
+// keeps its registered name, and names used by earlier versions can be kept as
+// aliases, so state persisted before the change still matches it.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	names map[reflect.Type]string
+	types map[string]reflect.Type // registered names and aliases
+}
+
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		names: make(map[reflect.Type]string),
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// Register records name, such as "orders.Order/v2", as the stable name of the type
+// of example. Each type and each name can only be registered once.
+func (r *TypeRegistry) Register(name string, example interface{}) error {
+	t := reflect.TypeOf(example)
+	if t == nil {
+		return fmt.Errorf("type %q: %w", name, ErrUntypedNil)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.names[t]; ok {
+		return fmt.Errorf("type %s already registered as %q", t, existing)
+	}
+	if _, ok := r.types[name]; ok {
+		return fmt.Errorf("type name %q already registered", name)
+	}
+	r.names[t] = name
+	r.types[name] = t
+	return nil
+}
+
+// Alias makes alias, typically the name a type was persisted under before it was
+// renamed, resolve to the type registered as name.
+func (r *TypeRegistry) Alias(alias, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.types[name]
+	if !ok {
+		return fmt.Errorf("type name %q not registered", name)
+	}
+	if _, ok := r.types[alias]; ok {
+		return fmt.Errorf("type name %q already registered", alias)
+	}
+	r.types[alias] = t
+	return nil
+}
+
+// Name returns the registered name of t, or its Go name if it is not registered.
+func (r *TypeRegistry) Name(t reflect.Type) string {
+	if name, ok := r.registeredName(t); ok {
+		return name
+	}
+	return t.String()
+}
+
+// registeredName returns the name t is registered under, if it is.
+func (r *TypeRegistry) registeredName(t reflect.Type) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.names[t]
+	return name, ok
+}
+
+// Lookup returns the type registered under name or one of its aliases.
+func (r *TypeRegistry) Lookup(name string) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.types[name]
+	return t, ok
+}
+
+// matches reports whether name, as persisted, designates t.
+func (r *TypeRegistry) matches(name string, t reflect.Type) bool {
+	if registered, ok := r.Lookup(name); ok {
+		return registered == t
+	}
+	return name == t.String()
+}
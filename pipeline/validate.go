@@ -0,0 +1,465 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ValidationIssue describes a single problem found by Validate.
+type ValidationIssue struct {
+	Step    string `json:"step,omitempty"`    // Empty for pipeline-level issues.
+	Param   int    `json:"param"`             // Parameter index, or -1 if the issue is not about a parameter.
+	Warning bool   `json:"warning,omitempty"` // Warnings do not make Validate fail.
+	Message string `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	switch {
+	case i.Step == "":
+		return i.Message
+	case i.Param < 0:
+		return fmt.Sprintf("step %s: %s", i.Step, i.Message)
+	default:
+		return fmt.Sprintf("step %s: param %d: %s", i.Step, i.Param, i.Message)
+	}
+}
+
+// ValidationError is returned by Validate and lists every error it found.
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		msgs[i] = issue.String()
+	}
+	return fmt.Sprintf("pipeline validation failed with %d issue(s): %s", len(e.Issues), strings.Join(msgs, "; "))
+}
+
+// valueRef identifies where a context value comes from: an initial input or a step output.
+type valueRef struct {
+	Step  string // Empty for initial inputs.
+	Index int    // Initial input index or output index of Step.
+}
+
+func (r valueRef) String() string {
+	if r.Step == "" {
+		return fmt.Sprintf("initial input %d", r.Index)
+	}
+	return fmt.Sprintf("output %d of step %s", r.Index, r.Step)
+}
+
+// paramResolution records how a parameter would be resolved, as computed by simulate.
+type paramResolution struct {
+	Step    string
+	Param   int
+	Type    reflect.Type
+	Binding ArgBinding
+	From    *valueRef // Nil for literal/env sources and unresolvable params.
+
+	Collected []valueRef // Values gathered into a slice parameter (fan-in).
+	Injected  bool       // Supplied by the engine (parallelism, logger, stream channel).
+	Defaulted bool       // Resolved from Binding.Default.
+	Issue     string     // Why the parameter does not resolve, if it does not.
+}
+
+// typeState tracks, by type only, what an execution would have stored in the context.
+type typeState struct {
+	values       map[reflect.Type][]valueRef
+	initialTypes []reflect.Type
+	stepOutputs  map[string][]reflect.Type
+	stored       []reflect.Type // type of each value in storage order
+	storedRefs   []valueRef
+	runners      map[string]bool // StepRunner steps, whose outputs are unknown
+
+	// Adapter steps planned so far, and those planned for the step being simulated.
+	adapters           []plannedAdapter
+	adapterIssues      []ValidationIssue
+	adapterResolutions []paramResolution
+}
+
+// plannedAdapter is an adapter step inserted to feed the step named before.
+type plannedAdapter struct {
+	step   Step
+	before string
+}
+
+func (s *typeState) store(t reflect.Type, ref valueRef) {
+	s.values[t] = append(s.values[t], ref)
+	s.stored = append(s.stored, t)
+	s.storedRefs = append(s.storedRefs, ref)
+}
+
+// candidates mirrors ExecutionContext.candidates.
+func (s *typeState) candidates(t reflect.Type) []valueRef {
+	if refs := s.values[t]; len(refs) > 0 || t.Kind() != reflect.Interface {
+		return refs
+	}
+	var refs []valueRef
+	for i, st := range s.stored {
+		if st.Implements(t) {
+			refs = append(refs, s.storedRefs[i])
+		}
+	}
+	return refs
+}
+
+// Validate simulates argument resolution for every step using only type information
+// (initial input types, step signatures and bindings) without calling any step.
+// Warnings are logged; if any errors are found a *ValidationError listing all of them is returned.
+func (p *Pipeline) Validate() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	issues, _ := p.simulate()
+	var errs []ValidationIssue
+	for _, issue := range issues {
+		if issue.Warning {
+			p.logger.Warnf("Validation: %s", issue)
+			continue
+		}
+		errs = append(errs, issue)
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Issues: errs}
+	}
+	return nil
+}
+
+// simulate walks the steps in execution order, resolving every parameter on types only.
+// It returns every error and warning found together with the resolution of each parameter.
+func (p *Pipeline) simulate() ([]ValidationIssue, []paramResolution) {
+	var issues []ValidationIssue
+	var resolutions []paramResolution
+	for _, name := range p.unknownOrderedSteps() {
+		issues = append(issues, ValidationIssue{Param: -1, Warning: true,
+			Message: fmt.Sprintf("step name %q in StepOrder does not exist in pipeline steps", name)})
+	}
+	issues = append(issues, p.cycleIssues()...)
+	issues = append(issues, p.chainIssues()...)
+	for _, entry := range p.config.OutputFilter {
+		if stepPosition(p.steps, entry) >= 0 {
+			continue // selects that step by name
+		}
+		_, filterErrs := compileOutputFilter([]string{entry})
+		for _, err := range filterErrs {
+			issues = append(issues, ValidationIssue{Param: -1, Message: err.Error()})
+		}
+	}
+
+	for _, err := range p.context.rejected {
+		issues = append(issues, ValidationIssue{Param: -1, Message: err.Error()})
+	}
+	stepIssues, resolutions := p.simulateSteps(p.simulateState())
+	issues = append(issues, stepIssues...)
+	issues = append(issues, sharedReaderIssues(resolutions)...)
+	return issues, resolutions
+}
+
+// simulateState returns the state of a simulation holding the initial inputs.
+func (p *Pipeline) simulateState() *typeState {
+	state := &typeState{
+		values:      make(map[reflect.Type][]valueRef),
+		stepOutputs: make(map[string][]reflect.Type),
+		runners:     make(map[string]bool),
+	}
+	for i, v := range p.context.InitialValues() {
+		state.store(v.Type(), valueRef{Index: i})
+		state.initialTypes = append(state.initialTypes, v.Type())
+	}
+	return state
+}
+
+// simulateSteps simulates the steps in execution order, with the adapter steps
+// planned on the way.
+func (p *Pipeline) simulateSteps(state *typeState) ([]ValidationIssue, []paramResolution) {
+	var issues []ValidationIssue
+	var resolutions []paramResolution
+	for _, step := range p.orderedSteps() {
+		if p.isDisabled(step.Name) {
+			continue // produces nothing
+		}
+		stepIssues, stepResolutions := p.simulateStep(step, state)
+		// Adapters planned while resolving the step run before it.
+		issues = append(issues, state.adapterIssues...)
+		resolutions = append(resolutions, state.adapterResolutions...)
+		state.adapterIssues, state.adapterResolutions = nil, nil
+		issues = append(issues, stepIssues...)
+		resolutions = append(resolutions, stepResolutions...)
+	}
+	return issues, resolutions
+}
+
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+// sharedReaderIssues warns about readers bound to more than one parameter. Values
+// are passed between steps by reference, so every consumer after the first would
+// read from wherever the previous one stopped.
+func sharedReaderIssues(resolutions []paramResolution) []ValidationIssue {
+	var issues []ValidationIssue
+	consumers := make(map[valueRef]string)
+	consume := func(res paramResolution, ref valueRef) {
+		if first, ok := consumers[ref]; ok {
+			issues = append(issues, ValidationIssue{Step: res.Step, Param: res.Param, Warning: true,
+				Message: fmt.Sprintf("reader from %s is already consumed by step %s", ref, first)})
+			return
+		}
+		consumers[ref] = res.Step
+	}
+	for _, res := range resolutions {
+		if res.From != nil && res.Type.Implements(readerType) {
+			consume(res, *res.From)
+		}
+		if res.Type.Kind() == reflect.Slice && res.Type.Elem().Implements(readerType) {
+			for _, ref := range res.Collected {
+				consume(res, ref)
+			}
+		}
+	}
+	return issues
+}
+
+func (p *Pipeline) simulateStep(step Step, state *typeState) ([]ValidationIssue, []paramResolution) {
+	if isRunner(step) {
+		// A StepRunner's inputs and outputs are only known when it runs.
+		state.runners[step.Name] = true
+		return nil, nil
+	}
+	fnType := reflect.TypeOf(step.Callable)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return []ValidationIssue{{Step: step.Name, Param: -1,
+			Message: fmt.Sprintf("callable of type %v is not a function", fnType)}}, nil
+	}
+
+	var bindings []*ArgBinding
+	if stepCfg, ok := p.config.StepConfigs[step.Name]; ok {
+		bindings = stepCfg.ArgBindings
+	}
+
+	var issues []ValidationIssue
+	var resolutions []paramResolution
+	picks := make(map[reflect.Type]int)
+	streaming := isStreamingStep(fnType)
+	for i := 0; i < fnType.NumIn(); i++ {
+		binding := ArgBinding{Source: ArgSourceDefault}
+		if i < len(bindings) && bindings[i] != nil {
+			binding = *bindings[i]
+		}
+		res := paramResolution{Step: step.Name, Param: i, Type: fnType.In(i), Binding: binding}
+		injected := res.Type == parallelismType || res.Type == loggerType || res.Type == workerPoolType ||
+			(streaming && isSendChan(res.Type))
+		if binding.Source == ArgSourceDefault && injected {
+			// Injected by the engine.
+			res.Injected = true
+			resolutions = append(resolutions, res)
+			continue
+		}
+		msg, warning := p.simulateArg(&res, state, picks)
+		if msg != "" && !warning && binding.Default != nil {
+			// Resolved from the binding's default instead.
+			res.From, res.Collected, res.Defaulted = nil, nil, true
+			msg = p.validateLiteral(res.Type, binding.Default)
+		}
+		if msg != "" {
+			res.Issue = msg
+			issues = append(issues, ValidationIssue{Step: step.Name, Param: i, Warning: warning, Message: msg})
+		}
+		resolutions = append(resolutions, res)
+	}
+
+	outs := make([]reflect.Type, fnType.NumOut())
+	for i := range outs {
+		outs[i] = fnType.Out(i)
+	}
+	if streaming {
+		outs = streamOutputTypes(fnType)
+	}
+	for i, out := range outs {
+		// Lazy outputs can only be reached through bindings naming the step.
+		if !p.isLazy(step) {
+			state.store(out, valueRef{Step: step.Name, Index: i})
+		}
+		state.stepOutputs[step.Name] = append(state.stepOutputs[step.Name], out)
+	}
+	return issues, resolutions
+}
+
+// simulateArg mirrors resolveArg on types only, filling res.From when the value comes
+// from the context. It returns an empty message if the argument resolves.
+func (p *Pipeline) simulateArg(res *paramResolution, state *typeState, picks map[reflect.Type]int) (msg string, warning bool) {
+	paramType, binding := res.Type, res.Binding
+
+	switch binding.Source {
+	case ArgSourceNamedInput:
+		index, ok := p.context.NamedInput(binding.Name)
+		if !ok {
+			return fmt.Sprintf("no initial input named %q", binding.Name), false
+		}
+		if t := state.initialTypes[index]; !p.convertible(t, paramType) {
+			return fmt.Sprintf("initial input %q has type %s, not assignable to %s", binding.Name, t, paramType), false
+		}
+		res.From = &valueRef{Index: index}
+		return "", false
+
+	case ArgSourceInitial:
+		if binding.Index < 0 || binding.Index >= len(state.initialTypes) {
+			return fmt.Sprintf("ArgSourceInitial index %d out of range (%d total)",
+				binding.Index, len(state.initialTypes)), false
+		}
+		if t := state.initialTypes[binding.Index]; !p.convertible(t, paramType) {
+			return fmt.Sprintf("initial input %d has type %s, not assignable to %s", binding.Index, t, paramType), false
+		}
+		res.From = &valueRef{Index: binding.Index}
+		return "", false
+
+	case ArgSourceFunctionOutput:
+		if state.runners[binding.Name] {
+			return fmt.Sprintf("outputs of StepRunner step %s are not checked", binding.Name), true
+		}
+		outputs, ok := state.stepOutputs[binding.Name]
+		if !ok {
+			return fmt.Sprintf("function %s has not run before this step", binding.Name), false
+		}
+		index := binding.Index
+		if binding.OutputName != "" {
+			index = p.outputNameIndex(binding.Name, binding.OutputName)
+			if index < 0 {
+				return fmt.Sprintf("function %s has no output named %q", binding.Name, binding.OutputName), false
+			}
+		}
+		if index < 0 || index >= len(outputs) {
+			return fmt.Sprintf("requested output index %d of function %s but it has %d outputs",
+				index, binding.Name, len(outputs)), false
+		}
+		if !p.convertible(outputs[index], paramType) {
+			return fmt.Sprintf("output type %s from function %s not assignable to %s",
+				outputs[index], binding.Name, paramType), false
+		}
+		res.From = &valueRef{Step: binding.Name, Index: index}
+		return "", false
+
+	case ArgSourceLiteral:
+		return p.validateLiteral(paramType, binding.Value), false
+
+	case ArgSourceCollect:
+		if paramType.Kind() != reflect.Slice {
+			return fmt.Sprintf("ArgSourceCollect requires a slice parameter, got %s", paramType), false
+		}
+		if len(binding.Names) == 0 {
+			res.Collected = state.values[paramType.Elem()]
+			return "", false
+		}
+		for _, name := range binding.Names {
+			if state.runners[name] {
+				return fmt.Sprintf("outputs of StepRunner step %s are not checked", name), true
+			}
+			outputs, ok := state.stepOutputs[name]
+			if !ok {
+				return fmt.Sprintf("function %s has not run before this step", name), false
+			}
+			for i, t := range outputs {
+				if p.assignable(t, paramType.Elem()) {
+					res.Collected = append(res.Collected, valueRef{Step: name, Index: i})
+				}
+			}
+		}
+		if len(res.Collected) == 0 {
+			return fmt.Sprintf("no output of type %s from steps %v", paramType.Elem(), binding.Names), true
+		}
+		return "", false
+
+	case ArgSourceEnv:
+		if _, isString := binding.Value.(string); binding.Value != nil && !isString {
+			return p.validateLiteral(paramType, binding.Value), false
+		}
+		if !envTypeSupported(paramType) {
+			return fmt.Sprintf("environment variable %s cannot be converted to %s", binding.Name, paramType), false
+		}
+		if _, set := os.LookupEnv(binding.Name); !set && binding.Value == nil {
+			return fmt.Sprintf("environment variable %s is currently unset and has no default", binding.Name), true
+		}
+		return "", false
+	}
+
+	switch p.config.MissingArgPolicy {
+	case MissingArgPolicyUseLatest, MissingArgPolicyZeroValue:
+		if canCollect(paramType, state.values) {
+			res.Collected = state.values[paramType.Elem()]
+			return "", false
+		}
+		srcType := paramType
+		if p.config.AllowConversion && len(state.candidates(paramType)) == 0 {
+			t, err := convertibleSource(paramType, state.values)
+			if err != nil {
+				return err.Error(), false
+			}
+			if t != nil {
+				srcType = t
+			}
+		}
+		refs := state.candidates(srcType)
+		if len(refs) == 0 {
+			adapter, ok, err := p.adapterStep(res.Step, paramType, state)
+			if err != nil {
+				return err.Error(), false
+			}
+			if ok {
+				adapterIssues, adapterResolutions := p.simulateStep(adapter, state)
+				state.adapters = append(state.adapters, plannedAdapter{step: adapter, before: res.Step})
+				state.adapterIssues = append(state.adapterIssues, adapterIssues...)
+				state.adapterResolutions = append(state.adapterResolutions, adapterResolutions...)
+				srcType, refs = paramType, state.candidates(paramType)
+			}
+		}
+		if len(refs) == 0 {
+			if p.config.MissingArgPolicy == MissingArgPolicyZeroValue {
+				return "", false
+			}
+			if len(state.runners) > 0 {
+				return fmt.Sprintf("no value of type %s is available unless a StepRunner step sets one", paramType), true
+			}
+			return fmt.Sprintf("no value of type %s is available", paramType), false
+		}
+		idx := picks[srcType]
+		if idx >= len(refs) {
+			idx = len(refs) - 1
+		}
+		if idx < len(refs)-1 {
+			picks[srcType] = idx + 1
+		}
+		res.From = &refs[idx]
+		if n := countStepRefs(refs, res.From.Step); res.From.Step != "" && n > 1 {
+			return fmt.Sprintf("resolved by position to output %d of step %s, which returns %d values of type %s; bind it with OutputName or Index",
+				res.From.Index, res.From.Step, n, srcType), true
+		}
+		return "", false
+	case MissingArgPolicyFail:
+		return fmt.Sprintf("missing argument for type %s (policy=fail)", paramType), false
+	default:
+		return "unknown MissingArgPolicy", false
+	}
+}
+
+// countStepRefs counts the values of refs output by step.
+func countStepRefs(refs []valueRef, step string) int {
+	n := 0
+	for _, ref := range refs {
+		if ref.Step == step {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *Pipeline) validateLiteral(paramType reflect.Type, value interface{}) string {
+	if value == nil {
+		return fmt.Sprintf("literal has no value for type %s", paramType)
+	}
+	if t := reflect.TypeOf(value); !p.assignable(t, paramType) {
+		return fmt.Sprintf("literal of type %s not assignable to %s", t, paramType)
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrWaitTimeout is returned when the WaitFor condition of a step does not hold within its timeout.
+var ErrWaitTimeout = errors.New("wait condition timed out")
+
+// defaultWaitInterval is the polling interval of wait conditions that do not set one.
+const defaultWaitInterval = time.Second
+
+// WaitCondition is an external condition a step waits for before running, such
+// as a file being delivered or a key published in a store. See StepConfig.WaitFor.
+type WaitCondition struct {
+	Name  string // Describes the condition in logs and errors.
+	Ready func(ctx context.Context) (bool, error)
+
+	// Interval separates checks (one second if zero). Timeout, if positive, fails
+	// the step with ErrWaitTimeout when the condition still does not hold; otherwise
+	// the step waits as long as the run's context allows.
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// KeyChecker reports whether a key is present in a store, such as an object store
+// or a key-value database.
+type KeyChecker interface {
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// ConditionFunc returns a condition holding when ready returns true. A failure
+// of ready fails the step.
+func ConditionFunc(name string, ready func(ctx context.Context) (bool, error)) WaitCondition {
+	return WaitCondition{Name: name, Ready: ready}
+}
+
+// FileExists returns a condition holding once path exists.
+func FileExists(path string) WaitCondition {
+	return WaitCondition{
+		Name: "file " + path,
+		Ready: func(context.Context) (bool, error) {
+			_, err := os.Stat(path)
+			if errors.Is(err, os.ErrNotExist) {
+				return false, nil
+			}
+			return err == nil, err
+		},
+	}
+}
+
+// KeyExists returns a condition holding once store has key.
+func KeyExists(store KeyChecker, key string) WaitCondition {
+	return WaitCondition{
+		Name:  "key " + key,
+		Ready: func(ctx context.Context) (bool, error) { return store.Exists(ctx, key) },
+	}
+}
+
+// WithWaitFor makes the step wait for cond before it runs; see StepConfig.WaitFor.
+func WithWaitFor(cond WaitCondition) StepOption {
+	return func(cfg *StepConfig) { cfg.WaitFor = &cond }
+}
+
+// waitFor blocks until the WaitFor condition of step holds, if it has one, and
+// returns how long it waited. Every check still failing is reported as a
+// ProgressStepWaiting event, and heartbeats meanwhile tell the step is waiting.
+func (p *Pipeline) waitFor(step Step, hb *heartbeat) (time.Duration, error) {
+	stepCfg, ok := p.config.StepConfigs[step.Name]
+	if !ok || stepCfg.WaitFor == nil || stepCfg.WaitFor.Ready == nil {
+		return 0, nil
+	}
+	cond := stepCfg.WaitFor
+	interval := cond.Interval
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+	ctx := p.runCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cond.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cond.Timeout)
+		defer cancel()
+	}
+
+	hb.setWaiting(true)
+	defer hb.setWaiting(false)
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for logged := false; ; logged = true {
+		ready, err := cond.Ready(ctx)
+		waited := time.Since(start)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			return waited, fmt.Errorf("wait for %s: %w", cond.Name, err)
+		case ready:
+			if logged {
+				p.logger.Infof("Condition %s holds after %s", cond.Name, waited.Round(time.Millisecond))
+			}
+			return waited, nil
+		case !logged:
+			p.logger.Infof("Waiting for %s", cond.Name)
+		}
+		p.progress(ProgressStepWaiting, step.Name, waited, nil)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if p.runCtx != nil && p.runCtx.Err() != nil {
+				return time.Since(start), p.runCtx.Err()
+			}
+			return time.Since(start), fmt.Errorf("wait for %s: %w after %s", cond.Name, ErrWaitTimeout, cond.Timeout)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package pipeline
+
+import "reflect"
+
+// workerPool bounds the goroutines working concurrently in the parallel regions of
+// a run, see PipelineConfig.Workers. A nil pool is unbounded.
+type workerPool struct {
+	slots chan struct{}
+}
+
+var workerPoolType = reflect.TypeOf((*workerPool)(nil))
+
+func newWorkerPool(n int) *workerPool {
+	if n <= 0 {
+		return nil
+	}
+	return &workerPool{slots: make(chan struct{}, n)}
+}
+
+// acquire blocks until a worker is free and reports true, or reports false if
+// abort is closed first.
+func (w *workerPool) acquire(abort <-chan struct{}) bool {
+	if w == nil {
+		return true
+	}
+	select {
+	case w.slots <- struct{}{}:
+		return true
+	case <-abort:
+		return false
+	}
+}
+
+func (w *workerPool) release() {
+	if w != nil {
+		<-w.slots
+	}
+}
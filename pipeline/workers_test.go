@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrency tracks the number of calls in progress and the most seen at once.
+type concurrency struct {
+	current, max atomic.Int32
+}
+
+func (c *concurrency) enter() {
+	n := c.current.Add(1)
+	for {
+		m := c.max.Load()
+		if n <= m || c.max.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	c.current.Add(-1)
+}
+
+func TestWorkersBoundAutoParallelBatch(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.AutoParallel = true
+	cfg.Workers = 2
+	p := NewPipeline(cfg, discardLogger)
+	var c concurrency
+	p.AddStep("a", func() int8 { c.enter(); return 0 })
+	p.AddStep("b", func() int16 { c.enter(); return 0 })
+	p.AddStep("c", func() int32 { c.enter(); return 0 })
+	p.AddStep("d", func() int64 { c.enter(); return 0 })
+	p.AddStep("e", func() uint8 { c.enter(); return 0 })
+	p.AddStep("f", func() uint16 { c.enter(); return 0 })
+
+	if _, err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := c.max.Load(); got > 2 {
+		t.Errorf("%d steps ran at once, want at most 2", got)
+	}
+}
+
+func TestWorkersBoundFanOut(t *testing.T) {
+	cfg := NewPipelineConfig()
+	cfg.Workers = 2
+	p := NewPipeline(cfg, discardLogger)
+	var c concurrency
+	if err := p.AddFanOutStep("square", func(n int) int { c.enter(); return n * n }, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	items := make([]int, 20)
+	result, err := p.Run(context.Background(), items)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := len(result.Steps[0].Outputs[0].([]int)); got != len(items) {
+		t.Errorf("square returned %d results, want %d", got, len(items))
+	}
+	// The step's own goroutine plus the two workers.
+	if got := c.max.Load(); got > 3 {
+		t.Errorf("%d items were processed at once, want at most 3", got)
+	}
+}